@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"gored/respser"
+)
+
+// block makes the running command wait for a list to be stored at one of
+// keys, for at most timeout unless it is 0, and returns nil to say so. The
+// command runs again each time one of the keys may be ready, and keeps
+// waiting until it gets a reply; if the time runs out, timeoutReply is the
+// reply instead. Inside EXEC nothing can wait, so that happens at once.
+func (c *client) block(keys []string, timeout time.Duration, timeoutReply respser.RespEncoder) respser.RespEncoder {
+	if c.inExec {
+		return timeoutReply
+	}
+	// A command that runs again after waking up keeps waiting where it
+	// was in the queues, and until the first deadline.
+	if c.waiter == nil {
+		c.waiter = c.db.Block(keys...)
+		c.deadline = time.Time{}
+		if timeout > 0 {
+			c.deadline = time.Now().Add(timeout)
+		}
+		c.timeoutReply = timeoutReply
+	}
+	return nil
+}
+
+// waitBlocked parks the client, without holding any locks, until one of
+// the keys of its blocked command may be ready, the timeout passes or the
+// client goes away, and returns what to reply then.
+func (c *client) waitBlocked(cmd *command, args []string) respser.RespEncoder {
+	// Replies to the commands pipelined before this one are not held back.
+	if err := c.writer.Flush(); err != nil {
+		c.unblock()
+		c.closeAfterReply = true
+		return nil
+	}
+
+	hangup, stopWatching := c.watchHangup()
+	defer stopWatching()
+	var timeout <-chan time.Time
+	if !c.deadline.IsZero() {
+		timer := time.NewTimer(time.Until(c.deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-c.waiter.Ready():
+		return call(c, cmd, args)
+	case <-timeout:
+		c.unblock()
+		return c.timeoutReply
+	case <-hangup:
+		c.unblock()
+		c.closeAfterReply = true
+		return nil
+	}
+}
+
+func (c *client) unblock() {
+	unlock := c.db.Lock(c.waiter.Keys()...)
+	c.db.Unblock(c.waiter)
+	unlock()
+	c.waiter = nil
+}
+
+// watchHangup watches for the client closing the connection while no
+// commands are being read, until stop is called. Input that arrives in the
+// meantime is read ahead and left for the next command, so a client that
+// pipelined more commands behind a blocked one is still noticed going
+// away.
+func (c *client) watchHangup() (hangup <-chan struct{}, stop func()) {
+	ch := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if c.input.readAhead() {
+			close(ch)
+		}
+	}()
+	return ch, func() {
+		// Cut the read short, then let reads block again.
+		c.conn.SetReadDeadline(time.Now())
+		<-done
+		c.conn.SetReadDeadline(time.Time{})
+	}
+}
+
+// parseTimeout parses the timeout of a blocking command, in seconds with
+// an optional fraction. As in Redis, it is cut to whole milliseconds and
+// 0 means waiting forever.
+func parseTimeout(s string) (time.Duration, *respser.ErrorString) {
+	f, ok := parseFloat(s)
+	if !ok {
+		return 0, errorReply("ERR timeout is not a float or out of range")
+	}
+	if f < 0 {
+		return 0, errorReply("ERR timeout is negative")
+	}
+	if f*1000 >= math.MaxInt64 {
+		return 0, errorReply("ERR timeout is out of range")
+	}
+	// Longer timeouts than a Duration holds are as good as forever.
+	ms := min(int64(f*1000), math.MaxInt64/int64(time.Millisecond))
+	return time.Duration(ms) * time.Millisecond, nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"gored/keyspace"
+	"gored/respser"
+)
+
+var lastClientID atomic.Int64
+
+// client holds the state of one connection for as long as it is open.
+type client struct {
+	id     int64
+	conn   net.Conn
+	input  *connReader
+	reader *respser.Reader
+	writer *respser.Writer
+	db     *keyspace.DB
+	cfg    *config
+
+	// protocol is the RESP version negotiated with HELLO. Replies are
+	// rendered for it just before they are written.
+	protocol int
+	name     string
+
+	closeAfterReply bool
+
+	// The transaction state: whether MULTI was called, the commands queued
+	// since, and whether one of them could not be queued, which fails EXEC.
+	inMulti    bool
+	queued     [][]string
+	multiError bool
+	// inExec is set while EXEC runs the queued commands, which must not
+	// block.
+	inExec bool
+
+	// The state of a blocked command: what it waits for, until when, and
+	// what it replies if the time runs out. The deadline is zero for
+	// commands that wait forever.
+	waiter       *keyspace.Waiter
+	deadline     time.Time
+	timeoutReply respser.RespEncoder
+}
+
+func newClient(conn net.Conn, cfg *config, db *keyspace.DB) *client {
+	c := &client{
+		id:       lastClientID.Add(1),
+		conn:     conn,
+		writer:   respser.NewWriter(conn),
+		db:       db,
+		cfg:      cfg,
+		protocol: 2,
+	}
+	c.input = &connReader{conn: conn, writer: c.writer}
+	c.reader = respser.NewReader(c.input)
+	c.reader.SetLimits(cfg.limits)
+	return c
+}
+
+// connReader is what a client reads its commands from. The replies written
+// so far are flushed before every read from the connection, which may
+// block until the client sends more, and the client may be waiting for
+// them first. Pipelined commands that already arrived are read from the
+// buffer instead, so their replies still go out in one write.
+//
+// While a command is blocked, readAhead keeps reading the connection to
+// notice the client going away; what it read is returned first, and the
+// error it ended with after that.
+type connReader struct {
+	conn   net.Conn
+	writer *respser.Writer
+	ahead  []byte
+	err    error
+}
+
+// maxReadAhead is the most input read ahead for a blocked client before it
+// is disconnected, as client-query-buffer-limit is by default in Redis.
+const maxReadAhead = 1 << 30
+
+func (r *connReader) Read(p []byte) (int, error) {
+	if len(r.ahead) > 0 {
+		n := copy(p, r.ahead)
+		r.ahead = r.ahead[n:]
+		if len(r.ahead) == 0 {
+			r.ahead = nil
+		}
+		return n, nil
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+	if err := r.writer.Flush(); err != nil {
+		return 0, err
+	}
+	return r.conn.Read(p)
+}
+
+// readAhead reads the connection until it fails, and reports whether that
+// was for another reason than a timeout, which is how it is stopped. It
+// must not run at the same time as Read.
+func (r *connReader) readAhead() bool {
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.conn.Read(buf)
+		r.ahead = append(r.ahead, buf[:n]...)
+		var nerr net.Error
+		switch {
+		case err != nil && errors.As(err, &nerr) && nerr.Timeout():
+			return false
+		case err != nil:
+			r.err = err
+			return true
+		case len(r.ahead) > maxReadAhead:
+			r.err = errors.New("read ahead limit reached")
+			return true
+		}
+	}
+}
+
+func handleConnection(conn net.Conn, cfg *config, db *keyspace.DB) {
+	c := newClient(conn, cfg, db)
+	defer c.conn.Close()
+	c.serve()
+}
+
+func (c *client) serve() {
+	for {
+		elements, err := c.reader.ReadCommand()
+		var args []string
+		if err == nil {
+			args, err = commandArgs(elements)
+		}
+		if err != nil {
+			c.readFailed(err)
+			return
+		}
+
+		reply := handleCommand(c, args)
+		if err := c.writeReply(reply); err != nil {
+			fmt.Println("Error writing:", err.Error())
+			return
+		}
+
+		// Other replies are flushed by connReader, before reading on.
+		if c.closeAfterReply {
+			if err := c.writer.Flush(); err != nil {
+				fmt.Println("Error writing:", err.Error())
+			}
+			return
+		}
+	}
+}
+
+// readFailed ends a connection whose input could not be read. Malformed
+// input is answered with a protocol error; either way the replies to the
+// commands read before the failure are sent.
+func (c *client) readFailed(err error) {
+	if perr := asProtocolError(err); perr != nil {
+		fmt.Println("Protocol error from client", c.id, "-", err)
+		c.writeReply(&respser.ErrorString{E: "ERR " + perr.Error()})
+	} else if !errors.Is(err, io.EOF) {
+		fmt.Println("Error reading:", err)
+	}
+	c.writer.Flush()
+}
+
+func (c *client) writeReply(reply respser.RespEncoder) error {
+	if reply == nil {
+		return nil
+	}
+	return c.writer.WriteValue(respser.ToProtocol(reply, c.protocol))
+}
+
+// commandArgs turns the elements of a request into command arguments. As
+// in Redis, every element must be a bulk string.
+func commandArgs(elements []respser.RespEncoder) ([]string, error) {
+	args := make([]string, 0, len(elements))
+	for _, e := range elements {
+		b, ok := e.(*respser.BulkString)
+		if !ok {
+			return nil, &protocolError{fmt.Sprintf("expected '$', got '%c'", e.RespEncode()[0])}
+		}
+		if b.S == nil {
+			args = append(args, "")
+		} else {
+			args = append(args, *b.S)
+		}
+	}
+	return args, nil
+}
+
+// authenticate checks credentials given to HELLO. There is no ACL support:
+// only the default user exists and it accepts any password.
+func authenticate(username, password string) bool {
+	return username == "default"
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"gored/keyspace"
+	"gored/respser"
+)
+
+// startServer serves connections on a local port, with a fresh DB, until
+// the test ends, and returns its address.
+func startServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected to listen, Got %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	cfg := &config{limits: respser.DefaultLimits, hz: 10}
+	db := keyspace.New()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go handleConnection(conn, cfg, db)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func dial(t *testing.T, addr string) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Expected to connect, Got %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func send(t *testing.T, conn net.Conn, input string) {
+	t.Helper()
+	if _, err := conn.Write([]byte(input)); err != nil {
+		t.Fatalf("Expected to send %q, Got %v", input, err)
+	}
+}
+
+// expectReply reads exactly len(want) bytes from conn, waiting at most
+// timeout for them, and checks that they are want.
+func expectReply(t *testing.T, conn net.Conn, want string, timeout time.Duration) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	defer conn.SetReadDeadline(time.Time{})
+	got := make([]byte, len(want))
+	n, err := io.ReadFull(conn, got)
+	if err != nil || string(got) != want {
+		t.Fatalf("Expected %q, Got %q (%v)", want, got[:n], err)
+	}
+}
+
+func TestRepliesNotHeldBack(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"inline_then_partial", "PING\r\nPI", "+PONG\r\n"},
+		{"resp_then_partial", "*1\r\n$4\r\nPING\r\n*2\r\n$3\r\nGE", "+PONG\r\n"},
+		{"pipelined", "PING\r\nECHO a\r\n", "+PONG\r\n$1\r\na\r\n"},
+	}
+
+	addr := startServer(t)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := dial(t, addr)
+			send(t, conn, tc.input)
+			expectReply(t, conn, tc.want, time.Second)
+		})
+	}
+}
+
+func TestRequestElements(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty_request", "*0\r\nPING\r\n", "+PONG\r\n"},
+		{"simple_string_element", "*1\r\n+PING\r\n", "-ERR Protocol error: expected '$', got '+'\r\n"},
+		{"integer_element", "*2\r\n$4\r\nECHO\r\n:1\r\n", "-ERR Protocol error: expected '$', got ':'\r\n"},
+		{"error_element", "*1\r\n-PING\r\n", "-ERR Protocol error: expected '$', got '-'\r\n"},
+	}
+
+	addr := startServer(t)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := dial(t, addr)
+			send(t, conn, tc.input)
+			expectReply(t, conn, tc.want, time.Second)
+		})
+	}
+}
+
+// TestBlockedHangupAfterPipelining checks that a blocked client that sent
+// more commands and then went away is noticed, so it is not handed the
+// next element pushed.
+func TestBlockedHangupAfterPipelining(t *testing.T) {
+	addr := startServer(t)
+
+	gone := dial(t, addr)
+	send(t, gone, "BLPOP eq 0\r\nPING\r\n")
+	// Let the server read the BLPOP and block before the connection goes.
+	time.Sleep(50 * time.Millisecond)
+	gone.Close()
+
+	waiting := dial(t, addr)
+	send(t, waiting, "BLPOP eq 5\r\n")
+	time.Sleep(50 * time.Millisecond)
+
+	pusher := dial(t, addr)
+	send(t, pusher, "RPUSH eq v1\r\n")
+	expectReply(t, pusher, ":1\r\n", time.Second)
+	expectReply(t, waiting, "*2\r\n$2\r\neq\r\n$2\r\nv1\r\n", time.Second)
+}
+
+// TestBlockedPipelinedCommands checks that commands sent behind a blocked
+// one run once it is served.
+func TestBlockedPipelinedCommands(t *testing.T) {
+	addr := startServer(t)
+
+	blocked := dial(t, addr)
+	send(t, blocked, "BLPOP q 5\r\nPING\r\n")
+	time.Sleep(50 * time.Millisecond)
+	send(t, blocked, "ECHO a\r\n")
+	time.Sleep(50 * time.Millisecond)
+
+	pusher := dial(t, addr)
+	send(t, pusher, "RPUSH q v1\r\n")
+	expectReply(t, blocked, "*2\r\n$1\r\nq\r\n$2\r\nv1\r\n+PONG\r\n$1\r\na\r\n", time.Second)
+}
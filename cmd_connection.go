@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"gored/respser"
+)
+
+func init() {
+	registerCommand("PING", pingCommand, -1, flagFast, 0, 0, 0)
+	registerCommand("ECHO", echoCommand, 2, flagFast, 0, 0, 0)
+	registerCommand("QUIT", quitCommand, -1, flagFast|flagNoQueue, 0, 0, 0)
+	registerCommand("HELLO", helloCommand, -1, flagFast, 0, 0, 0)
+}
+
+// pingCommand implements PING [message].
+func pingCommand(c *client, args []string) respser.RespEncoder {
+	switch len(args) {
+	case 1:
+		return &respser.SimpleString{S: "PONG"}
+	case 2:
+		return respser.NewBulkString(args[1])
+	default:
+		return wrongArityReply("ping")
+	}
+}
+
+func echoCommand(c *client, args []string) respser.RespEncoder {
+	return respser.NewBulkString(args[1])
+}
+
+func quitCommand(c *client, args []string) respser.RespEncoder {
+	c.closeAfterReply = true
+	return &respser.SimpleString{S: "OK"}
+}
+
+// helloCommand implements HELLO [protover [AUTH username password] [SETNAME clientname]].
+func helloCommand(c *client, args []string) respser.RespEncoder {
+	protocol := c.protocol
+	if len(args) > 1 {
+		ver, err := strconv.Atoi(args[1])
+		if err != nil {
+			return &respser.ErrorString{E: "ERR Protocol version is not an integer or out of range"}
+		}
+		if ver < 2 || ver > 3 {
+			return &respser.ErrorString{E: "NOPROTO unsupported protocol version"}
+		}
+		protocol = ver
+	}
+
+	name, setName := "", false
+	for i := 2; i < len(args); i++ {
+		opt := args[i]
+		switch {
+		case strings.EqualFold(opt, "AUTH") && i+2 < len(args):
+			if !authenticate(args[i+1], args[i+2]) {
+				return &respser.ErrorString{E: "WRONGPASS invalid username-password pair or user is disabled."}
+			}
+			i += 2
+		case strings.EqualFold(opt, "SETNAME") && i+1 < len(args):
+			name, setName = args[i+1], true
+			if strings.ContainsFunc(name, func(r rune) bool { return r <= ' ' || r > '~' }) {
+				return &respser.ErrorString{E: "ERR Client names cannot contain spaces, newlines or special characters."}
+			}
+			i++
+		default:
+			return errorReply("ERR Syntax error in HELLO option '%s'", opt)
+		}
+	}
+
+	c.protocol = protocol
+	if setName {
+		c.name = name
+	}
+
+	reply, err := respser.Marshal(helloReply{
+		Server:  "redis",
+		Version: serverVersion,
+		Proto:   c.protocol,
+		ID:      c.id,
+		Mode:    "standalone",
+		Role:    "master",
+		Modules: []string{},
+	})
+	if err != nil {
+		return &respser.ErrorString{E: "ERR " + err.Error()}
+	}
+	return reply
+}
+
+type helloReply struct {
+	Server  string   `resp:"server"`
+	Version string   `resp:"version"`
+	Proto   int      `resp:"proto"`
+	ID      int64    `resp:"id"`
+	Mode    string   `resp:"mode"`
+	Role    string   `resp:"role"`
+	Modules []string `resp:"modules"`
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestConnectionCommands(t *testing.T) {
+	runCases(t, []commandCase{
+		{"ping", []string{"PING"}, "+PONG\r\n"},
+		{"ping_message", []string{"PING hi"}, "$2\r\nhi\r\n"},
+		{"ping_too_many_args", []string{"PING a b"}, "-ERR wrong number of arguments for 'ping' command\r\n"},
+		{"echo", []string{"ECHO \"a b\""}, "$3\r\na b\r\n"},
+		{"quit", []string{"QUIT"}, "+OK\r\n"},
+		{"hello_3_switches_protocol", []string{"HELLO 3", "GET k"}, "_\r\n"},
+		{"hello_2_switches_back", []string{"HELLO 3", "HELLO 2", "GET k"}, "$-1\r\n"},
+		{"hello_bad_version", []string{"HELLO x"}, "-ERR Protocol version is not an integer or out of range\r\n"},
+		{"hello_unsupported_version", []string{"HELLO 4"}, "-NOPROTO unsupported protocol version\r\n"},
+		{"hello_bad_name", []string{"HELLO 3 SETNAME \"a b\""}, "-ERR Client names cannot contain spaces, newlines or special characters.\r\n"},
+		{"hello_bad_option", []string{"HELLO 3 FOO"}, "-ERR Syntax error in HELLO option 'FOO'\r\n"},
+		{"hello_missing_value", []string{"HELLO 3 SETNAME"}, "-ERR Syntax error in HELLO option 'SETNAME'\r\n"},
+	})
+}
+
+func TestDispatchErrors(t *testing.T) {
+	runCases(t, []commandCase{
+		{"unknown_command", []string{"FOO a b"}, "-ERR unknown command 'FOO', with args beginning with: 'a' 'b' \r\n"},
+		{"unknown_command_no_args", []string{"FOO"}, "-ERR unknown command 'FOO', with args beginning with: \r\n"},
+		{"wrong_arity_fixed", []string{"GET"}, "-ERR wrong number of arguments for 'get' command\r\n"},
+		{"wrong_arity_minimum", []string{"SET k"}, "-ERR wrong number of arguments for 'set' command\r\n"},
+		{"case_insensitive", []string{"pInG"}, "+PONG\r\n"},
+		{"queued_arity_error", []string{"MULTI", "GET"}, "-ERR wrong number of arguments for 'get' command\r\n"},
+	})
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"math"
+	"strings"
+
+	"gored/respser"
+)
+
+func init() {
+	registerCommand("EXPIRE", expireCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("PEXPIRE", pexpireCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("EXPIREAT", expireatCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("PEXPIREAT", pexpireatCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("TTL", ttlCommand, 2, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("PTTL", pttlCommand, 2, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("EXPIRETIME", expiretimeCommand, 2, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("PEXPIRETIME", pexpiretimeCommand, 2, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("PERSIST", persistCommand, 2, flagWrite|flagFast, 1, 1, 1)
+}
+
+func expireCommand(c *client, args []string) respser.RespEncoder {
+	return expireGeneric(c, args, false, true)
+}
+
+func pexpireCommand(c *client, args []string) respser.RespEncoder {
+	return expireGeneric(c, args, false, false)
+}
+
+func expireatCommand(c *client, args []string) respser.RespEncoder {
+	return expireGeneric(c, args, true, true)
+}
+
+func pexpireatCommand(c *client, args []string) respser.RespEncoder {
+	return expireGeneric(c, args, true, false)
+}
+
+// expireGeneric implements the EXPIRE family:
+// EXPIRE key time [NX|XX|GT|LT]. The time is absolute for the *AT variants
+// and counted in seconds unless the variant starts with P.
+func expireGeneric(c *client, args []string, absolute, seconds bool) respser.RespEncoder {
+	var nx, xx, gt, lt bool
+	for _, arg := range args[3:] {
+		switch strings.ToUpper(arg) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "GT":
+			gt = true
+		case "LT":
+			lt = true
+		default:
+			return errorReply("ERR Unsupported option %s", arg)
+		}
+	}
+	if nx && (xx || gt || lt) {
+		return errorReply("ERR NX and XX, GT or LT options at the same time are not compatible")
+	}
+	if gt && lt {
+		return errorReply("ERR GT and LT options at the same time are not compatible")
+	}
+
+	when, ok := parseInt64(args[2])
+	if !ok {
+		return errorReply(errNotInteger)
+	}
+	invalid := func() respser.RespEncoder {
+		return invalidExpireReply(strings.ToLower(args[0]))
+	}
+	if seconds {
+		if when > math.MaxInt64/1000 || when < math.MinInt64/1000 {
+			return invalid()
+		}
+		when *= 1000
+	}
+	if !absolute {
+		now := c.db.Now()
+		if when > 0 && now > math.MaxInt64-when || when < 0 && now < math.MinInt64-when {
+			return invalid()
+		}
+		when += now
+	}
+
+	key := args[1]
+	if !c.db.Exists(key) {
+		return &respser.Integer{N: 0}
+	}
+	// A key without an expire time counts as living forever, so GT never
+	// applies to it and LT always does.
+	current, volatile := c.db.Expire(key)
+	if nx && volatile || xx && !volatile || gt && (!volatile || when <= current) || lt && volatile && when >= current {
+		return &respser.Integer{N: 0}
+	}
+	c.db.SetExpire(key, when)
+	return &respser.Integer{N: 1}
+}
+
+func ttlCommand(c *client, args []string) respser.RespEncoder {
+	return ttlGeneric(c, args[1], false, true)
+}
+
+func pttlCommand(c *client, args []string) respser.RespEncoder {
+	return ttlGeneric(c, args[1], false, false)
+}
+
+func expiretimeCommand(c *client, args []string) respser.RespEncoder {
+	return ttlGeneric(c, args[1], true, true)
+}
+
+func pexpiretimeCommand(c *client, args []string) respser.RespEncoder {
+	return ttlGeneric(c, args[1], true, false)
+}
+
+// ttlGeneric replies with the time to live of key, or with its expire time
+// if absolute is set: -2 if the key does not exist and -1 if it has no
+// expire time.
+func ttlGeneric(c *client, key string, absolute, seconds bool) respser.RespEncoder {
+	if !c.db.Exists(key) {
+		return &respser.Integer{N: -2}
+	}
+	at, ok := c.db.Expire(key)
+	if !ok {
+		return &respser.Integer{N: -1}
+	}
+	if absolute {
+		if seconds {
+			at /= 1000
+		}
+		return &respser.Integer{N: at}
+	}
+	ttl := max(at-c.db.Now(), 0)
+	if seconds {
+		ttl = (ttl + 500) / 1000
+	}
+	return &respser.Integer{N: ttl}
+}
+
+func persistCommand(c *client, args []string) respser.RespEncoder {
+	if !c.db.Exists(args[1]) || !c.db.Persist(args[1]) {
+		return &respser.Integer{N: 0}
+	}
+	return &respser.Integer{N: 1}
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestExpire(t *testing.T) {
+	runCases(t, []commandCase{
+		{"expire_missing", []string{"EXPIRE k 100"}, ":0\r\n"},
+		{"expire_ttl", []string{"SET k v", "EXPIRE k 100", "TTL k"}, ":100\r\n"},
+		{"pexpire_pttl", []string{"SET k v", "PEXPIRE k 100000", "TTL k"}, ":100\r\n"},
+		{"expire_negative_deletes", []string{"SET k v", "EXPIRE k -1", "GET k"}, "$-1\r\n"},
+		{"expireat_past_deletes", []string{"SET k v", "EXPIREAT k 1", "GET k"}, "$-1\r\n"},
+		{"expiretime", []string{"SET k v", "EXPIREAT k 33177117420", "EXPIRETIME k"}, ":33177117420\r\n"},
+		{"pexpiretime", []string{"SET k v", "PEXPIREAT k 33177117420000", "PEXPIRETIME k"}, ":33177117420000\r\n"},
+		{"expiretime_no_ttl", []string{"SET k v", "EXPIRETIME k"}, ":-1\r\n"},
+		{"ttl_no_ttl", []string{"SET k v", "TTL k"}, ":-1\r\n"},
+		{"ttl_missing", []string{"TTL k"}, ":-2\r\n"},
+		{"pttl_missing", []string{"PTTL k"}, ":-2\r\n"},
+		{"nx_with_ttl", []string{"SET k v EX 100", "EXPIRE k 200 NX"}, ":0\r\n"},
+		{"nx_without_ttl", []string{"SET k v", "EXPIRE k 200 NX"}, ":1\r\n"},
+		{"xx_without_ttl", []string{"SET k v", "EXPIRE k 200 XX"}, ":0\r\n"},
+		{"gt_lower", []string{"SET k v EX 100", "EXPIRE k 50 GT", "TTL k"}, ":100\r\n"},
+		{"gt_higher", []string{"SET k v EX 100", "EXPIRE k 200 GT"}, ":1\r\n"},
+		{"gt_without_ttl", []string{"SET k v", "EXPIRE k 200 GT"}, ":0\r\n"},
+		{"lt_without_ttl", []string{"SET k v", "EXPIRE k 200 LT"}, ":1\r\n"},
+		{"nx_and_xx", []string{"SET k v", "EXPIRE k 100 NX XX"}, "-ERR NX and XX, GT or LT options at the same time are not compatible\r\n"},
+		{"gt_and_lt", []string{"SET k v", "EXPIRE k 100 GT LT"}, "-ERR GT and LT options at the same time are not compatible\r\n"},
+		{"unknown_option", []string{"SET k v", "EXPIRE k 100 FOO"}, "-ERR Unsupported option FOO\r\n"},
+		{"not_integer", []string{"SET k v", "EXPIRE k 1.5"}, "-ERR value is not an integer or out of range\r\n"},
+		{"overflow", []string{"SET k v", "EXPIRE k 9223372036854775807"}, "-ERR invalid expire time in 'expire' command\r\n"},
+		{"persist", []string{"SET k v EX 100", "PERSIST k", "TTL k"}, ":-1\r\n"},
+		{"persist_no_ttl", []string{"SET k v", "PERSIST k"}, ":0\r\n"},
+	})
+}
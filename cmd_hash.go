@@ -0,0 +1,355 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"gored/keyspace"
+	"gored/respser"
+)
+
+func init() {
+	registerCommand("HSET", hsetCommand, -4, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HMSET", hsetCommand, -4, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HSETNX", hsetnxCommand, 4, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HGET", hgetCommand, 3, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("HMGET", hmgetCommand, -3, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("HDEL", hdelCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HEXISTS", hexistsCommand, 3, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("HLEN", hlenCommand, 2, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("HSTRLEN", hstrlenCommand, 3, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("HKEYS", hkeysCommand, 2, flagReadonly, 1, 1, 1)
+	registerCommand("HVALS", hvalsCommand, 2, flagReadonly, 1, 1, 1)
+	registerCommand("HGETALL", hgetallCommand, 2, flagReadonly, 1, 1, 1)
+	registerCommand("HINCRBY", hincrbyCommand, 4, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HINCRBYFLOAT", hincrbyfloatCommand, 4, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HRANDFIELD", hrandfieldCommand, -2, flagReadonly, 1, 1, 1)
+}
+
+// hashForWrite returns the hash stored at key, creating it if needed.
+func hashForWrite(c *client, key string) (*keyspace.Hash, error) {
+	h, ok, err := c.db.GetHash(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		h = keyspace.NewHash()
+		c.db.Set(key, h)
+	}
+	return h, nil
+}
+
+// hsetCommand implements HSET and HMSET key field value [field value ...],
+// which differ in their reply: HSET counts the new fields.
+func hsetCommand(c *client, args []string) respser.RespEncoder {
+	if len(args)%2 != 0 {
+		return wrongArityReply(args[0])
+	}
+	h, err := hashForWrite(c, args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	var created int64
+	for i := 2; i < len(args); i += 2 {
+		if h.Set(args[i], args[i+1]) {
+			created++
+		}
+	}
+	if strings.EqualFold(args[0], "HMSET") {
+		return &respser.SimpleString{S: "OK"}
+	}
+	return &respser.Integer{N: created}
+}
+
+func hsetnxCommand(c *client, args []string) respser.RespEncoder {
+	h, err := hashForWrite(c, args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if _, ok := h.Get(args[2]); ok {
+		return &respser.Integer{N: 0}
+	}
+	h.Set(args[2], args[3])
+	return &respser.Integer{N: 1}
+}
+
+func hgetCommand(c *client, args []string) respser.RespEncoder {
+	h, ok, err := c.db.GetHash(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.BulkString{}
+	}
+	return fieldReply(h, args[2])
+}
+
+func hmgetCommand(c *client, args []string) respser.RespEncoder {
+	h, ok, err := c.db.GetHash(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	for _, field := range args[2:] {
+		if !ok {
+			reply.AddElement(&respser.BulkString{})
+			continue
+		}
+		reply.AddElement(fieldReply(h, field))
+	}
+	return reply
+}
+
+func hdelCommand(c *client, args []string) respser.RespEncoder {
+	key := args[1]
+	h, ok, err := c.db.GetHash(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	var deleted int64
+	for _, field := range args[2:] {
+		if h.Delete(field) {
+			deleted++
+		}
+	}
+	if h.Len() == 0 {
+		c.db.Delete(key)
+	}
+	return &respser.Integer{N: deleted}
+}
+
+func hexistsCommand(c *client, args []string) respser.RespEncoder {
+	h, ok, err := c.db.GetHash(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	if _, ok := h.Get(args[2]); !ok {
+		return &respser.Integer{N: 0}
+	}
+	return &respser.Integer{N: 1}
+}
+
+func hlenCommand(c *client, args []string) respser.RespEncoder {
+	h, ok, err := c.db.GetHash(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	return &respser.Integer{N: int64(h.Len())}
+}
+
+func hstrlenCommand(c *client, args []string) respser.RespEncoder {
+	h, ok, err := c.db.GetHash(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	v, _ := h.Get(args[2])
+	return &respser.Integer{N: int64(len(v))}
+}
+
+func hkeysCommand(c *client, args []string) respser.RespEncoder {
+	return hashListing(c, args[1], func(field, v string) []string { return []string{field} })
+}
+
+func hvalsCommand(c *client, args []string) respser.RespEncoder {
+	return hashListing(c, args[1], func(field, v string) []string { return []string{v} })
+}
+
+// hashListing replies with an array of what elems returns for each field
+// of the hash at key.
+func hashListing(c *client, key string, elems func(field, v string) []string) respser.RespEncoder {
+	h, ok, err := c.db.GetHash(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	if !ok {
+		return reply
+	}
+	h.Range(func(field, v string) bool {
+		for _, e := range elems(field, v) {
+			reply.AddElement(respser.NewBulkString(e))
+		}
+		return true
+	})
+	return reply
+}
+
+// hgetallCommand replies with a map, which becomes a flat array of fields
+// and values for RESP2 clients.
+func hgetallCommand(c *client, args []string) respser.RespEncoder {
+	h, ok, err := c.db.GetHash(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	reply := &respser.Map{Entries: []respser.MapEntry{}}
+	if !ok {
+		return reply
+	}
+	h.Range(func(field, v string) bool {
+		reply.Entries = append(reply.Entries, respser.MapEntry{Key: respser.NewBulkString(field), Value: respser.NewBulkString(v)})
+		return true
+	})
+	return reply
+}
+
+func hincrbyCommand(c *client, args []string) respser.RespEncoder {
+	incr, ok := parseInt64(args[3])
+	if !ok {
+		return errorReply(errNotInteger)
+	}
+	h, err := hashForWrite(c, args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	var n int64
+	if v, ok := h.Get(args[2]); ok {
+		if n, ok = parseInt64(v); !ok {
+			return errorReply("ERR hash value is not an integer")
+		}
+	}
+	if incr > 0 && n > math.MaxInt64-incr || incr < 0 && n < math.MinInt64-incr {
+		return errorReply("ERR increment or decrement would overflow")
+	}
+	n += incr
+	h.Update(args[2], strconv.FormatInt(n, 10))
+	return &respser.Integer{N: n}
+}
+
+func hincrbyfloatCommand(c *client, args []string) respser.RespEncoder {
+	incr, ok := parseFloat(args[3])
+	if !ok {
+		return errorReply("ERR value is not a valid float")
+	}
+	if math.IsInf(incr, 0) {
+		return errorReply("ERR value is NaN or Infinity")
+	}
+	h, err := hashForWrite(c, args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	var f float64
+	if v, ok := h.Get(args[2]); ok {
+		if f, ok = parseFloat(v); !ok {
+			return errorReply("ERR hash value is not a float")
+		}
+	}
+	f += incr
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return errorReply("ERR increment would produce NaN or Infinity")
+	}
+	v := strconv.FormatFloat(f, 'f', -1, 64)
+	h.Update(args[2], v)
+	return respser.NewBulkString(v)
+}
+
+// hrandfieldCommand implements HRANDFIELD key [count [WITHVALUES]]. A
+// positive count asks for that many distinct fields, or all of them if
+// there are fewer, and a negative one for -count fields that may repeat.
+func hrandfieldCommand(c *client, args []string) respser.RespEncoder {
+	if len(args) > 4 || len(args) == 4 && !strings.EqualFold(args[3], "WITHVALUES") {
+		return errorReply(errSyntax)
+	}
+	withValues := len(args) == 4
+	h, ok, err := c.db.GetHash(args[1])
+	if len(args) == 2 {
+		if err != nil {
+			return errorReply(errWrongType)
+		}
+		if !ok {
+			return &respser.BulkString{}
+		}
+		field, _, _ := h.Random()
+		return respser.NewBulkString(field)
+	}
+
+	count, parsed := parseInt64(args[2])
+	if !parsed {
+		return errorReply(errNotInteger)
+	}
+	if withValues && (count < -math.MaxInt64/2 || count > math.MaxInt64/2) {
+		return errorReply("ERR value is out of range")
+	}
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	if !ok {
+		return reply
+	}
+	add := func(field, v string) {
+		switch {
+		case !withValues:
+			reply.AddElement(respser.NewBulkString(field))
+		case c.protocol >= 3:
+			// RESP3 clients get each field with its value as a pair.
+			reply.AddElement(&respser.Array{Elements: &[]respser.RespEncoder{respser.NewBulkString(field), respser.NewBulkString(v)}})
+		default:
+			reply.AddElement(respser.NewBulkString(field))
+			reply.AddElement(respser.NewBulkString(v))
+		}
+	}
+
+	if count < 0 {
+		for ; count < 0; count++ {
+			field, v, _ := h.Random()
+			add(field, v)
+		}
+		return reply
+	}
+	if count >= int64(h.Len()) {
+		h.Range(func(field, v string) bool {
+			add(field, v)
+			return true
+		})
+		return reply
+	}
+	// Asking for most of the fields, picking at random until there are
+	// enough distinct ones could take a while, so those are shuffled
+	// instead.
+	if count*3 > int64(h.Len()) {
+		fields := make([]string, 0, h.Len())
+		h.Range(func(field, _ string) bool {
+			fields = append(fields, field)
+			return true
+		})
+		rand.Shuffle(len(fields), func(i, j int) { fields[i], fields[j] = fields[j], fields[i] })
+		for _, field := range fields[:count] {
+			v, _ := h.Get(field)
+			add(field, v)
+		}
+		return reply
+	}
+	picked := map[string]bool{}
+	for int64(len(picked)) < count {
+		field, v, _ := h.Random()
+		if !picked[field] {
+			picked[field] = true
+			add(field, v)
+		}
+	}
+	return reply
+}
+
+// fieldReply replies with the value of field, or with the null bulk string
+// if the hash does not have it.
+func fieldReply(h *keyspace.Hash, field string) respser.RespEncoder {
+	v, ok := h.Get(field)
+	if !ok {
+		return &respser.BulkString{}
+	}
+	return respser.NewBulkString(v)
+}
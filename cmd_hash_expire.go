@@ -0,0 +1,351 @@
+package main
+
+import (
+	"strings"
+
+	"gored/keyspace"
+	"gored/respser"
+)
+
+// maxFieldExpire is the latest expire time, in unix milliseconds, that a
+// hash field can have in Redis.
+const maxFieldExpire = (1<<48 - 1) >> 2
+
+func init() {
+	registerCommand("HEXPIRE", hexpireCommand, -6, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HPEXPIRE", hpexpireCommand, -6, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HEXPIREAT", hexpireatCommand, -6, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HPEXPIREAT", hpexpireatCommand, -6, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HTTL", httlCommand, -5, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("HPTTL", hpttlCommand, -5, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("HEXPIRETIME", hexpiretimeCommand, -5, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("HPEXPIRETIME", hpexpiretimeCommand, -5, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("HPERSIST", hpersistCommand, -5, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HGETEX", hgetexCommand, -5, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("HSETEX", hsetexCommand, -6, flagWrite, 1, 1, 1)
+}
+
+func hexpireCommand(c *client, args []string) respser.RespEncoder {
+	return hexpireGeneric(c, args, false, true)
+}
+
+func hpexpireCommand(c *client, args []string) respser.RespEncoder {
+	return hexpireGeneric(c, args, false, false)
+}
+
+func hexpireatCommand(c *client, args []string) respser.RespEncoder {
+	return hexpireGeneric(c, args, true, true)
+}
+
+func hpexpireatCommand(c *client, args []string) respser.RespEncoder {
+	return hexpireGeneric(c, args, true, false)
+}
+
+// hexpireGeneric implements the HEXPIRE family:
+// HEXPIRE key time [NX|XX|GT|LT] FIELDS numfields field [field ...]. The
+// time is as in expireGeneric. The reply has for each field -2 if there is
+// no such field, 0 if the condition was not met, 1 if the expire time was
+// set and 2 if the field was deleted because the time was not in the
+// future.
+func hexpireGeneric(c *client, args []string, absolute, seconds bool) respser.RespEncoder {
+	when, ok := parseInt64(args[2])
+	if !ok {
+		return errorReply(errNotInteger)
+	}
+	fieldsAt := 3
+	cond := strings.ToUpper(args[3])
+	switch cond {
+	case "NX", "XX", "GT", "LT":
+		fieldsAt++
+	default:
+		cond = ""
+	}
+	fields, errReply := parseFields(args, fieldsAt, 1)
+	if errReply != nil {
+		return errReply
+	}
+
+	if when < 0 {
+		return errorReply("ERR invalid expire time, must be >= 0")
+	}
+	invalid := func() respser.RespEncoder {
+		return invalidExpireReply(strings.ToLower(args[0]))
+	}
+	if seconds {
+		if when > maxFieldExpire/1000 {
+			return invalid()
+		}
+		when *= 1000
+	}
+	now := c.db.Now()
+	if !absolute {
+		if when > maxFieldExpire-now {
+			return invalid()
+		}
+		when += now
+	}
+	if when > maxFieldExpire {
+		return invalid()
+	}
+
+	key := args[1]
+	h, exists, err := c.db.GetHash(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	return eachField(h, exists, fields, func(field string) respser.RespEncoder {
+		// As for keys, a field without an expire time lives forever.
+		current, volatile := h.Expire(field)
+		switch {
+		case cond == "NX" && volatile, cond == "XX" && !volatile,
+			cond == "GT" && (!volatile || when <= current), cond == "LT" && volatile && when >= current:
+			return &respser.Integer{N: 0}
+		}
+		c.db.SetFieldExpire(key, field, when)
+		if when <= now {
+			return &respser.Integer{N: 2}
+		}
+		return &respser.Integer{N: 1}
+	})
+}
+
+func httlCommand(c *client, args []string) respser.RespEncoder {
+	return httlGeneric(c, args, false, true)
+}
+
+func hpttlCommand(c *client, args []string) respser.RespEncoder {
+	return httlGeneric(c, args, false, false)
+}
+
+func hexpiretimeCommand(c *client, args []string) respser.RespEncoder {
+	return httlGeneric(c, args, true, true)
+}
+
+func hpexpiretimeCommand(c *client, args []string) respser.RespEncoder {
+	return httlGeneric(c, args, true, false)
+}
+
+// httlGeneric implements HTTL key FIELDS numfields field [field ...] and
+// its variants, which reply as ttlGeneric does for each field.
+func httlGeneric(c *client, args []string, absolute, seconds bool) respser.RespEncoder {
+	fields, errReply := parseFields(args, 2, 1)
+	if errReply != nil {
+		return errReply
+	}
+	h, exists, err := c.db.GetHash(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	now := c.db.Now()
+	return eachField(h, exists, fields, func(field string) respser.RespEncoder {
+		at, ok := h.Expire(field)
+		if !ok {
+			return &respser.Integer{N: -1}
+		}
+		if absolute {
+			if seconds {
+				at /= 1000
+			}
+			return &respser.Integer{N: at}
+		}
+		ttl := max(at-now, 0)
+		if seconds {
+			// Unlike TTL, HTTL rounds up, as Redis does.
+			ttl = (ttl + 999) / 1000
+		}
+		return &respser.Integer{N: ttl}
+	})
+}
+
+// hpersistCommand implements HPERSIST key FIELDS numfields field
+// [field ...]. The reply has for each field -2 if there is no such field,
+// -1 if it has no expire time and 1 if its expire time was removed.
+func hpersistCommand(c *client, args []string) respser.RespEncoder {
+	fields, errReply := parseFields(args, 2, 1)
+	if errReply != nil {
+		return errReply
+	}
+	h, exists, err := c.db.GetHash(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	return eachField(h, exists, fields, func(field string) respser.RespEncoder {
+		if !h.Persist(field) {
+			return &respser.Integer{N: -1}
+		}
+		return &respser.Integer{N: 1}
+	})
+}
+
+// hgetexCommand implements
+// HGETEX key [EX seconds|PX milliseconds|EXAT unix-time-seconds|PXAT unix-time-milliseconds|PERSIST]
+// FIELDS numfields field [field ...], which replies like HMGET and then
+// changes the expire time of the fields that exist.
+func hgetexCommand(c *client, args []string) respser.RespEncoder {
+	opt, when := "", int64(0)
+	i := 2
+	for ; i < len(args) && !strings.EqualFold(args[i], "FIELDS"); i++ {
+		o := strings.ToUpper(args[i])
+		switch {
+		case o == "PERSIST" && opt == "":
+			opt = o
+		case isExpireOption(o) && opt == "" && i+1 < len(args):
+			var errReply *respser.ErrorString
+			if when, errReply = fieldExpireTime(c.db, o, args[i+1], "hgetex"); errReply != nil {
+				return errReply
+			}
+			opt = o
+			i++
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+	fields, errReply := parseFields(args, i, 1)
+	if errReply != nil {
+		return errReply
+	}
+
+	key := args[1]
+	h, exists, err := c.db.GetHash(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	for _, field := range fields {
+		if !exists {
+			reply.AddElement(&respser.BulkString{})
+			continue
+		}
+		reply.AddElement(fieldReply(h, field))
+		// Deleting the last field deletes the key, so the fields still
+		// there are checked first.
+		if _, ok := h.Get(field); !ok {
+			continue
+		}
+		switch opt {
+		case "":
+		case "PERSIST":
+			h.Persist(field)
+		default:
+			c.db.SetFieldExpire(key, field, when)
+		}
+	}
+	return reply
+}
+
+// hsetexCommand implements
+// HSETEX key [FNX|FXX] [EX seconds|PX milliseconds|EXAT unix-time-seconds|PXAT unix-time-milliseconds|KEEPTTL]
+// FIELDS numfields field value [field value ...]. FNX sets the fields only
+// if none of them exist and FXX only if all of them do, and the reply says
+// whether they were set. Without an expire option or KEEPTTL, the fields
+// lose their expire time, as with HSET.
+func hsetexCommand(c *client, args []string) respser.RespEncoder {
+	cond, opt, when := "", "", int64(0)
+	i := 2
+	for ; i < len(args) && !strings.EqualFold(args[i], "FIELDS"); i++ {
+		o := strings.ToUpper(args[i])
+		switch {
+		case (o == "FNX" || o == "FXX") && cond == "":
+			cond = o
+		case o == "KEEPTTL" && opt == "":
+			opt = o
+		case isExpireOption(o) && opt == "" && i+1 < len(args):
+			var errReply *respser.ErrorString
+			if when, errReply = fieldExpireTime(c.db, o, args[i+1], "hsetex"); errReply != nil {
+				return errReply
+			}
+			opt = o
+			i++
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+	pairs, errReply := parseFields(args, i, 2)
+	if errReply != nil {
+		return errReply
+	}
+
+	key := args[1]
+	h, exists, err := c.db.GetHash(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if cond != "" {
+		for j := 0; j < len(pairs); j += 2 {
+			found := false
+			if exists {
+				_, found = h.Get(pairs[j])
+			}
+			if found == (cond == "FNX") {
+				return &respser.Integer{N: 0}
+			}
+		}
+	}
+	if !exists {
+		h = keyspace.NewHash()
+		c.db.Set(key, h)
+	}
+	for j := 0; j < len(pairs); j += 2 {
+		if opt == "KEEPTTL" {
+			h.Update(pairs[j], pairs[j+1])
+		} else {
+			h.Set(pairs[j], pairs[j+1])
+		}
+	}
+	// A time in the past deletes the fields, and the key after the last
+	// one, so the values are all stored first.
+	if opt != "" && opt != "KEEPTTL" {
+		for j := 0; j < len(pairs); j += 2 {
+			if _, ok := h.Get(pairs[j]); ok {
+				c.db.SetFieldExpire(key, pairs[j], when)
+			}
+		}
+	}
+	return &respser.Integer{N: 1}
+}
+
+// parseFields parses FIELDS numfields at args[at], followed by per
+// arguments for each field, and returns those arguments.
+func parseFields(args []string, at, per int) ([]string, *respser.ErrorString) {
+	if at+1 >= len(args) || !strings.EqualFold(args[at], "FIELDS") {
+		return nil, errorReply("ERR Mandatory argument FIELDS is missing or not at the right position")
+	}
+	n, ok := parseInt64(args[at+1])
+	if !ok || n < 1 {
+		return nil, errorReply("ERR Number of fields must be a positive integer")
+	}
+	rest := args[at+2:]
+	if len(rest)%per != 0 || n != int64(len(rest)/per) {
+		return nil, errorReply("ERR The `numfields` parameter must match the number of arguments")
+	}
+	return rest, nil
+}
+
+// fieldExpireTime is expireTime for hash fields, which cannot expire as
+// late as keys.
+func fieldExpireTime(db *keyspace.DB, opt, arg, cmd string) (int64, *respser.ErrorString) {
+	when, errReply := expireTime(db, opt, arg, cmd)
+	if errReply == nil && when > maxFieldExpire {
+		return 0, invalidExpireReply(cmd)
+	}
+	return when, errReply
+}
+
+// eachField replies with an array of what fn returns for each of fields
+// in the hash h, or -2 for a field h does not have. fn may delete the
+// field, and with it the key.
+func eachField(h *keyspace.Hash, exists bool, fields []string, fn func(field string) respser.RespEncoder) respser.RespEncoder {
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	for _, field := range fields {
+		if !exists {
+			reply.AddElement(&respser.Integer{N: -2})
+			continue
+		}
+		if _, ok := h.Get(field); !ok {
+			reply.AddElement(&respser.Integer{N: -2})
+			continue
+		}
+		reply.AddElement(fn(field))
+	}
+	return reply
+}
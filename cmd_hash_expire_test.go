@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestHashFieldExpire(t *testing.T) {
+	runCases(t, []commandCase{
+		{"hexpire", []string{"HSET h a 1 b 2", "HEXPIRE h 100 FIELDS 3 a b c"}, "*3\r\n:1\r\n:1\r\n:-2\r\n"},
+		{"hexpire_missing_key", []string{"HEXPIRE h 100 FIELDS 2 a b"}, "*2\r\n:-2\r\n:-2\r\n"},
+		{"hexpire_zero_deletes", []string{"HSET h a 1 b 2", "HEXPIRE h 0 FIELDS 1 a"}, "*1\r\n:2\r\n"},
+		{"hexpire_last_field_deletes_key", []string{"HSET h a 1", "HEXPIRE h 0 FIELDS 1 a", "EXISTS h"}, ":0\r\n"},
+		{"hexpireat_past", []string{"HSET h a 1 b 2", "HEXPIREAT h 1 FIELDS 1 a", "HGETALL h"}, "*2\r\n$1\r\nb\r\n$1\r\n2\r\n"},
+		{"httl", []string{"HSET h a 1 b 2", "HEXPIRE h 100 FIELDS 1 a", "HTTL h FIELDS 3 a b c"}, "*3\r\n:100\r\n:-1\r\n:-2\r\n"},
+		{"hpttl_rounding", []string{"HSET h a 1", "HPEXPIRE h 1500 FIELDS 1 a", "HTTL h FIELDS 1 a"}, "*1\r\n:2\r\n"},
+		{"hexpiretime", []string{"HSET h a 1", "HEXPIREAT h 33177117420 FIELDS 1 a", "HEXPIRETIME h FIELDS 1 a"}, "*1\r\n:33177117420\r\n"},
+		{"hpexpiretime", []string{"HSET h a 1", "HPEXPIREAT h 33177117420123 FIELDS 1 a", "HPEXPIRETIME h FIELDS 1 a"}, "*1\r\n:33177117420123\r\n"},
+		{"hexpire_nx", []string{"HSET h a 1 b 2", "HEXPIRE h 100 FIELDS 1 a", "HEXPIRE h 200 NX FIELDS 2 a b"}, "*2\r\n:0\r\n:1\r\n"},
+		{"hexpire_xx", []string{"HSET h a 1 b 2", "HEXPIRE h 100 FIELDS 1 a", "HEXPIRE h 200 XX FIELDS 2 a b"}, "*2\r\n:1\r\n:0\r\n"},
+		{"hexpire_gt", []string{"HSET h a 1 b 2", "HEXPIRE h 100 FIELDS 1 a", "HEXPIRE h 50 GT FIELDS 2 a b"}, "*2\r\n:0\r\n:0\r\n"},
+		{"hexpire_lt", []string{"HSET h a 1 b 2", "HEXPIRE h 100 FIELDS 1 a", "HEXPIRE h 50 LT FIELDS 2 a b"}, "*2\r\n:1\r\n:1\r\n"},
+		{"hexpire_negative", []string{"HSET h a 1", "HEXPIRE h -1 FIELDS 1 a"}, "-ERR invalid expire time, must be >= 0\r\n"},
+		{"hexpire_too_late", []string{"HSET h a 1", "HEXPIRE h 9223372036854775 FIELDS 1 a"}, "-ERR invalid expire time in 'hexpire' command\r\n"},
+		{"hexpire_no_fields", []string{"HSET h a 1", "HEXPIRE h 100 NX a b c"}, "-ERR Mandatory argument FIELDS is missing or not at the right position\r\n"},
+		{"hexpire_zero_fields", []string{"HSET h a 1", "HEXPIRE h 100 FIELDS 0 a"}, "-ERR Number of fields must be a positive integer\r\n"},
+		{"hexpire_numfields_mismatch", []string{"HSET h a 1", "HEXPIRE h 100 FIELDS 2 a"}, "-ERR The `numfields` parameter must match the number of arguments\r\n"},
+		{"hexpire_wrong_type", []string{"SET h v", "HEXPIRE h 100 FIELDS 1 a"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"hpersist", []string{"HSET h a 1 b 2", "HEXPIRE h 100 FIELDS 1 a", "HPERSIST h FIELDS 3 a b c"}, "*3\r\n:1\r\n:-1\r\n:-2\r\n"},
+		{"hset_clears_field_ttl", []string{"HSET h a 1", "HEXPIRE h 100 FIELDS 1 a", "HSET h a 2", "HTTL h FIELDS 1 a"}, "*1\r\n:-1\r\n"},
+		{"hincrby_keeps_field_ttl", []string{"HSET h a 1", "HEXPIRE h 100 FIELDS 1 a", "HINCRBY h a 1", "HTTL h FIELDS 1 a"}, "*1\r\n:100\r\n"},
+	})
+}
+
+func TestHgetexHsetex(t *testing.T) {
+	runCases(t, []commandCase{
+		{"hgetex", []string{"HSET h a 1", "HGETEX h EX 100 FIELDS 2 a b"}, "*2\r\n$1\r\n1\r\n$-1\r\n"},
+		{"hgetex_sets_ttl", []string{"HSET h a 1", "HGETEX h EX 100 FIELDS 1 a", "HTTL h FIELDS 1 a"}, "*1\r\n:100\r\n"},
+		{"hgetex_persist", []string{"HSET h a 1", "HEXPIRE h 100 FIELDS 1 a", "HGETEX h PERSIST FIELDS 1 a", "HTTL h FIELDS 1 a"}, "*1\r\n:-1\r\n"},
+		{"hgetex_past_deletes", []string{"HSET h a 1", "HGETEX h PXAT 1 FIELDS 1 a", "EXISTS h"}, ":0\r\n"},
+		{"hgetex_missing_key", []string{"HGETEX h EX 100 FIELDS 1 a"}, "*1\r\n$-1\r\n"},
+		{"hgetex_two_options", []string{"HSET h a 1", "HGETEX h EX 100 PERSIST FIELDS 1 a"}, "-ERR syntax error\r\n"},
+		{"hgetex_zero", []string{"HSET h a 1", "HGETEX h EX 0 FIELDS 1 a"}, "-ERR invalid expire time in 'hgetex' command\r\n"},
+		{"hsetex", []string{"HSETEX h EX 100 FIELDS 2 a 1 b 2", "HTTL h FIELDS 2 a b"}, "*2\r\n:100\r\n:100\r\n"},
+		{"hsetex_clears_ttl", []string{"HSETEX h EX 100 FIELDS 1 a 1", "HSETEX h FIELDS 1 a 2", "HTTL h FIELDS 1 a"}, "*1\r\n:-1\r\n"},
+		{"hsetex_keepttl", []string{"HSETEX h EX 100 FIELDS 1 a 1", "HSETEX h KEEPTTL FIELDS 1 a 2", "HTTL h FIELDS 1 a"}, "*1\r\n:100\r\n"},
+		{"hsetex_fnx_existing", []string{"HSET h a 1", "HSETEX h FNX FIELDS 2 a 2 b 2"}, ":0\r\n"},
+		{"hsetex_fnx_sets_none", []string{"HSET h a 1", "HSETEX h FNX FIELDS 2 a 2 b 2", "HLEN h"}, ":1\r\n"},
+		{"hsetex_fxx_missing", []string{"HSET h a 1", "HSETEX h FXX FIELDS 2 a 2 b 2"}, ":0\r\n"},
+		{"hsetex_fxx", []string{"HSET h a 1", "HSETEX h FXX FIELDS 1 a 2", "HGET h a"}, "$1\r\n2\r\n"},
+		{"hsetex_fnx_fxx", []string{"HSETEX h FNX FXX FIELDS 1 a 1"}, "-ERR syntax error\r\n"},
+		{"hsetex_numfields_mismatch", []string{"HSETEX h FIELDS 2 a 1 b"}, "-ERR The `numfields` parameter must match the number of arguments\r\n"},
+	})
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestHashCommands(t *testing.T) {
+	runCases(t, []commandCase{
+		{"hset_new_fields", []string{"HSET h a 1 b 2", "HSET h b 3 c 4"}, ":1\r\n"},
+		{"hset_odd_args", []string{"HSET h a 1 b"}, "-ERR wrong number of arguments for 'hset' command\r\n"},
+		{"hmset", []string{"HMSET h a 1"}, "+OK\r\n"},
+		{"hget", []string{"HSET h a 1", "HGET h a"}, "$1\r\n1\r\n"},
+		{"hget_missing_field", []string{"HSET h a 1", "HGET h b"}, "$-1\r\n"},
+		{"hget_wrong_type", []string{"SET h v", "HGET h a"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"hsetnx_existing", []string{"HSET h a 1", "HSETNX h a 2", "HGET h a"}, "$1\r\n1\r\n"},
+		{"hsetnx_new", []string{"HSETNX h a 2"}, ":1\r\n"},
+		{"hmget", []string{"HSET h a 1", "HMGET h a b"}, "*2\r\n$1\r\n1\r\n$-1\r\n"},
+		{"hmget_missing_key", []string{"HMGET h a"}, "*1\r\n$-1\r\n"},
+		{"hdel", []string{"HSET h a 1 b 2", "HDEL h a c"}, ":1\r\n"},
+		{"hdel_last_deletes", []string{"HSET h a 1", "HDEL h a", "EXISTS h"}, ":0\r\n"},
+		{"hexists", []string{"HSET h a 1", "HEXISTS h a"}, ":1\r\n"},
+		{"hlen", []string{"HSET h a 1 b 2", "HLEN h"}, ":2\r\n"},
+		{"hstrlen", []string{"HSET h a hello", "HSTRLEN h a"}, ":5\r\n"},
+		{"hkeys", []string{"HSET h a 1", "HKEYS h"}, "*1\r\n$1\r\na\r\n"},
+		{"hvals", []string{"HSET h a 1", "HVALS h"}, "*1\r\n$1\r\n1\r\n"},
+		{"hgetall", []string{"HSET h a 1", "HGETALL h"}, "*2\r\n$1\r\na\r\n$1\r\n1\r\n"},
+		{"hgetall_resp3", []string{"HELLO 3", "HSET h a 1", "HGETALL h"}, "%1\r\n$1\r\na\r\n$1\r\n1\r\n"},
+		{"hgetall_missing", []string{"HGETALL h"}, "*0\r\n"},
+		{"hgetall_missing_resp3", []string{"HELLO 3", "HGETALL h"}, "%0\r\n"},
+		{"hincrby", []string{"HSET h a 5", "HINCRBY h a -7"}, ":-2\r\n"},
+		{"hincrby_missing", []string{"HINCRBY h a 3"}, ":3\r\n"},
+		{"hincrby_not_integer", []string{"HSET h a x", "HINCRBY h a 1"}, "-ERR hash value is not an integer\r\n"},
+		{"hincrby_overflow", []string{"HSET h a 9223372036854775807", "HINCRBY h a 1"}, "-ERR increment or decrement would overflow\r\n"},
+		{"hincrby_bad_increment", []string{"HINCRBY h a x"}, "-ERR value is not an integer or out of range\r\n"},
+		{"hincrbyfloat", []string{"HSET h a 10.5", "HINCRBYFLOAT h a 0.1"}, "$4\r\n10.6\r\n"},
+		{"hincrbyfloat_not_float", []string{"HSET h a x", "HINCRBYFLOAT h a 1"}, "-ERR hash value is not a float\r\n"},
+		{"hincrbyfloat_bad_increment", []string{"HINCRBYFLOAT h a x"}, "-ERR value is not a valid float\r\n"},
+		{"hincrbyfloat_inf", []string{"HINCRBYFLOAT h a inf"}, "-ERR value is NaN or Infinity\r\n"},
+		{"hincrbyfloat_underflow", []string{"HINCRBYFLOAT h a 1e-400"}, "-ERR value is not a valid float\r\n"},
+		{"hrandfield", []string{"HSET h a 1", "HRANDFIELD h"}, "$1\r\na\r\n"},
+		{"hrandfield_missing", []string{"HRANDFIELD h"}, "$-1\r\n"},
+		{"hrandfield_count", []string{"HSET h a 1", "HRANDFIELD h 5"}, "*1\r\n$1\r\na\r\n"},
+		{"hrandfield_negative_count", []string{"HSET h a 1", "HRANDFIELD h -2 WITHVALUES"}, "*4\r\n$1\r\na\r\n$1\r\n1\r\n$1\r\na\r\n$1\r\n1\r\n"},
+		{"hrandfield_count_missing", []string{"HRANDFIELD h 2"}, "*0\r\n"},
+		{"hrandfield_bad_option", []string{"HSET h a 1", "HRANDFIELD h 1 FOO"}, "-ERR syntax error\r\n"},
+	})
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"gored/glob"
+	"gored/keyspace"
+	"gored/respser"
+)
+
+func init() {
+	registerCommand("DEL", delCommand, -2, flagWrite, 1, -1, 1)
+	registerCommand("UNLINK", delCommand, -2, flagWrite|flagFast, 1, -1, 1)
+	registerCommand("EXISTS", existsCommand, -2, flagReadonly|flagFast, 1, -1, 1)
+	registerCommand("TOUCH", existsCommand, -2, flagReadonly|flagFast, 1, -1, 1)
+	registerCommand("TYPE", typeCommand, 2, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("RENAME", renameCommand, 3, flagWrite, 1, 2, 1)
+	registerCommand("RENAMENX", renamenxCommand, 3, flagWrite|flagFast, 1, 2, 1)
+	registerCommand("COPY", copyCommand, -3, flagWrite, 1, 2, 1)
+	registerCommand("RANDOMKEY", randomkeyCommand, 1, flagReadonly, 0, 0, 0)
+	registerCommand("DBSIZE", dbsizeCommand, 1, flagReadonly|flagFast, 0, 0, 0)
+	registerCommand("KEYS", keysCommand, 2, flagReadonly, 0, 0, 0)
+	registerCommand("SCAN", scanCommand, -2, flagReadonly, 0, 0, 0)
+	registerCommand("HSCAN", hscanCommand, -3, flagReadonly, 1, 1, 1)
+	registerCommand("SSCAN", sscanCommand, -3, flagReadonly, 1, 1, 1)
+	registerCommand("ZSCAN", zscanCommand, -3, flagReadonly, 1, 1, 1)
+}
+
+// delCommand implements DEL and UNLINK, which only differ in Redis by
+// whether the memory is freed in the background.
+func delCommand(c *client, args []string) respser.RespEncoder {
+	var n int64
+	for _, key := range args[1:] {
+		if c.db.Delete(key) {
+			n++
+		}
+	}
+	return &respser.Integer{N: n}
+}
+
+// existsCommand implements EXISTS and TOUCH. A key given several times is
+// counted every time. There is no access time to update, so TOUCH is just a
+// count of the existing keys.
+func existsCommand(c *client, args []string) respser.RespEncoder {
+	var n int64
+	for _, key := range args[1:] {
+		if c.db.Exists(key) {
+			n++
+		}
+	}
+	return &respser.Integer{N: n}
+}
+
+func typeCommand(c *client, args []string) respser.RespEncoder {
+	v, ok := c.db.Get(args[1])
+	if !ok {
+		return &respser.SimpleString{S: "none"}
+	}
+	return &respser.SimpleString{S: v.Type().String()}
+}
+
+func renameCommand(c *client, args []string) respser.RespEncoder {
+	if !c.db.Rename(args[1], args[2]) {
+		return errorReply("ERR no such key")
+	}
+	return &respser.SimpleString{S: "OK"}
+}
+
+func renamenxCommand(c *client, args []string) respser.RespEncoder {
+	src, dst := args[1], args[2]
+	if !c.db.Exists(src) {
+		return errorReply("ERR no such key")
+	}
+	if c.db.Exists(dst) {
+		return &respser.Integer{N: 0}
+	}
+	c.db.Rename(src, dst)
+	return &respser.Integer{N: 1}
+}
+
+// copyCommand implements COPY source destination [DB destination-db] [REPLACE].
+// There is a single database, so DB only accepts 0.
+func copyCommand(c *client, args []string) respser.RespEncoder {
+	replace := false
+	for i := 3; i < len(args); i++ {
+		switch opt := strings.ToUpper(args[i]); {
+		case opt == "REPLACE":
+			replace = true
+		case opt == "DB" && i+1 < len(args):
+			i++
+			n, ok := parseInt64(args[i])
+			if !ok {
+				return errorReply(errNotInteger)
+			}
+			if n != 0 {
+				return errorReply("ERR DB index is out of range")
+			}
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+
+	src, dst := args[1], args[2]
+	if src == dst {
+		return errorReply("ERR source and destination objects are the same")
+	}
+	if !c.db.Exists(src) || c.db.Exists(dst) && !replace {
+		return &respser.Integer{N: 0}
+	}
+	c.db.Copy(src, dst)
+	return &respser.Integer{N: 1}
+}
+
+func randomkeyCommand(c *client, args []string) respser.RespEncoder {
+	key, ok := c.db.RandomKey()
+	if !ok {
+		return &respser.BulkString{}
+	}
+	return respser.NewBulkString(key)
+}
+
+func dbsizeCommand(c *client, args []string) respser.RespEncoder {
+	return &respser.Integer{N: int64(c.db.Len())}
+}
+
+func keysCommand(c *client, args []string) respser.RespEncoder {
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	c.db.Range(func(key string, _ keyspace.Value) bool {
+		if glob.Match(args[1], key) {
+			reply.AddElement(respser.NewBulkString(key))
+		}
+		return true
+	})
+	return reply
+}
+
+// scanOptions are the options of the SCAN family:
+// [MATCH pattern] [COUNT count] [TYPE type], of which only SCAN takes TYPE.
+type scanOptions struct {
+	match      string
+	count      int
+	typ        keyspace.Type
+	filterType bool
+}
+
+func parseScanOptions(args []string, withType bool) (scanOptions, *respser.ErrorString) {
+	opts := scanOptions{match: "*", count: 10}
+	for i := 0; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		if i+1 == len(args) {
+			return opts, errorReply(errSyntax)
+		}
+		i++
+		switch {
+		case opt == "MATCH":
+			opts.match = args[i]
+		case opt == "COUNT":
+			n, ok := parseInt64(args[i])
+			if !ok {
+				return opts, errorReply(errNotInteger)
+			}
+			if n < 1 {
+				return opts, errorReply(errSyntax)
+			}
+			opts.count = int(min(n, 1<<31))
+		case opt == "TYPE" && withType:
+			t, ok := parseType(args[i])
+			if !ok {
+				return opts, errorReply("ERR unknown type name '%s'", args[i])
+			}
+			opts.typ, opts.filterType = t, true
+		default:
+			return opts, errorReply(errSyntax)
+		}
+	}
+	return opts, nil
+}
+
+func parseType(name string) (keyspace.Type, bool) {
+	for t := keyspace.TypeString; t <= keyspace.TypeZSet; t++ {
+		if strings.EqualFold(name, t.String()) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+func parseCursor(s string) (uint64, *respser.ErrorString) {
+	cursor, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, errorReply("ERR invalid cursor")
+	}
+	return cursor, nil
+}
+
+// scanSteps calls step, which takes one step of a SCAN iteration and
+// returns how many elements it saw, until count elements were seen, the
+// iteration ended, or ten times count steps saw fewer, as Redis does.
+func scanSteps(cursor uint64, count int, step func(cursor uint64) (uint64, int)) uint64 {
+	seen := 0
+	for i := 0; i < count*10; i++ {
+		var n int
+		cursor, n = step(cursor)
+		seen += n
+		if cursor == 0 || seen >= count {
+			break
+		}
+	}
+	return cursor
+}
+
+func scanReply(cursor uint64, elems []respser.RespEncoder) respser.RespEncoder {
+	return &respser.Array{Elements: &[]respser.RespEncoder{
+		respser.NewBulkString(strconv.FormatUint(cursor, 10)),
+		&respser.Array{Elements: &elems},
+	}}
+}
+
+// scanCommand implements SCAN cursor [MATCH pattern] [COUNT count] [TYPE type].
+func scanCommand(c *client, args []string) respser.RespEncoder {
+	cursor, errReply := parseCursor(args[1])
+	if errReply != nil {
+		return errReply
+	}
+	opts, errReply := parseScanOptions(args[2:], true)
+	if errReply != nil {
+		return errReply
+	}
+
+	elems := []respser.RespEncoder{}
+	cursor = scanSteps(cursor, opts.count, func(cursor uint64) (uint64, int) {
+		n := 0
+		cursor = c.db.Scan(cursor, func(key string, v keyspace.Value) {
+			n++
+			if (!opts.filterType || v.Type() == opts.typ) && glob.Match(opts.match, key) {
+				elems = append(elems, respser.NewBulkString(key))
+			}
+		})
+		return cursor, n
+	})
+	return scanReply(cursor, elems)
+}
+
+func hscanCommand(c *client, args []string) respser.RespEncoder {
+	return scanValue(c, args, keyspace.TypeHash)
+}
+
+func sscanCommand(c *client, args []string) respser.RespEncoder {
+	return scanValue(c, args, keyspace.TypeSet)
+}
+
+func zscanCommand(c *client, args []string) respser.RespEncoder {
+	return scanValue(c, args, keyspace.TypeZSet)
+}
+
+// scanValue implements HSCAN, SSCAN and ZSCAN:
+// key cursor [MATCH pattern] [COUNT count], iterating over the elements of
+// the value of type t stored at key. MATCH looks at the field or member of
+// an element only.
+func scanValue(c *client, args []string, t keyspace.Type) respser.RespEncoder {
+	cursor, errReply := parseCursor(args[2])
+	if errReply != nil {
+		return errReply
+	}
+	v, ok := c.db.Get(args[1])
+	if !ok {
+		return scanReply(0, []respser.RespEncoder{})
+	}
+	var scan func(cursor uint64, fn func(elem ...string)) uint64
+	switch v := v.(type) {
+	case *keyspace.ZSet:
+		scan = func(cursor uint64, fn func(elem ...string)) uint64 {
+			return v.Scan(cursor, func(m string, score float64) {
+				fn(m, respser.FormatDouble(score))
+			})
+		}
+	case keyspace.Scanner:
+		scan = v.Scan
+	}
+	if v.Type() != t || scan == nil {
+		return errorReply(errWrongType)
+	}
+	opts, errReply := parseScanOptions(args[3:], false)
+	if errReply != nil {
+		return errReply
+	}
+
+	elems := []respser.RespEncoder{}
+	cursor = scanSteps(cursor, opts.count, func(cursor uint64) (uint64, int) {
+		n := 0
+		cursor = scan(cursor, func(elem ...string) {
+			n++
+			if !glob.Match(opts.match, elem[0]) {
+				return
+			}
+			for _, e := range elem {
+				elems = append(elems, respser.NewBulkString(e))
+			}
+		})
+		return cursor, n
+	})
+	return scanReply(cursor, elems)
+}
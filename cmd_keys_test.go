@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+
+	"gored/respser"
+)
+
+func TestKeyCommands(t *testing.T) {
+	runCases(t, []commandCase{
+		{"del", []string{"MSET a 1 b 2", "DEL a b c"}, ":2\r\n"},
+		{"unlink", []string{"SET a 1", "UNLINK a", "EXISTS a"}, ":0\r\n"},
+		{"exists_repeated", []string{"SET a 1", "EXISTS a a b"}, ":2\r\n"},
+		{"touch", []string{"SET a 1", "TOUCH a b"}, ":1\r\n"},
+		{"type_string", []string{"SET a 1", "TYPE a"}, "+string\r\n"},
+		{"type_list", []string{"RPUSH a 1", "TYPE a"}, "+list\r\n"},
+		{"type_missing", []string{"TYPE a"}, "+none\r\n"},
+		{"rename", []string{"SET a 1", "RENAME a b", "GET b"}, "$1\r\n1\r\n"},
+		{"rename_keeps_ttl", []string{"SET a 1 EX 100", "RENAME a b", "TTL b"}, ":100\r\n"},
+		{"rename_overwrites", []string{"SET a 1", "SET b 2 EX 100", "RENAME a b", "TTL b"}, ":-1\r\n"},
+		{"rename_same_key", []string{"SET a 1", "RENAME a a", "GET a"}, "$1\r\n1\r\n"},
+		{"rename_missing", []string{"RENAME a b"}, "-ERR no such key\r\n"},
+		{"renamenx", []string{"SET a 1", "RENAMENX a b"}, ":1\r\n"},
+		{"renamenx_existing", []string{"SET a 1", "SET b 2", "RENAMENX a b"}, ":0\r\n"},
+		{"renamenx_missing", []string{"RENAMENX a b"}, "-ERR no such key\r\n"},
+		{"copy", []string{"SET a 1", "COPY a b", "GET b"}, "$1\r\n1\r\n"},
+		{"copy_is_deep", []string{"RPUSH a x", "COPY a b", "RPUSH b y", "LLEN a"}, ":1\r\n"},
+		{"copy_existing", []string{"SET a 1", "SET b 2", "COPY a b"}, ":0\r\n"},
+		{"copy_replace", []string{"SET a 1", "SET b 2", "COPY a b REPLACE", "GET b"}, "$1\r\n1\r\n"},
+		{"copy_missing", []string{"COPY a b"}, ":0\r\n"},
+		{"copy_same_key", []string{"SET a 1", "COPY a a"}, "-ERR source and destination objects are the same\r\n"},
+		{"copy_db", []string{"SET a 1", "COPY a b DB 1"}, "-ERR DB index is out of range\r\n"},
+		{"copy_bad_option", []string{"SET a 1", "COPY a b FOO"}, "-ERR syntax error\r\n"},
+		{"randomkey", []string{"SET a 1", "RANDOMKEY"}, "$1\r\na\r\n"},
+		{"randomkey_empty", []string{"RANDOMKEY"}, "$-1\r\n"},
+		{"dbsize", []string{"MSET a 1 b 2", "DBSIZE"}, ":2\r\n"},
+	})
+}
+
+func TestKeysScan(t *testing.T) {
+	runCases(t, []commandCase{
+		{"keys", []string{"MSET foo 1 bar 2", "KEYS f*"}, "*1\r\n$3\r\nfoo\r\n"},
+		{"keys_none", []string{"SET foo 1", "KEYS x*"}, "*0\r\n"},
+		{"scan", []string{"SET foo 1", "SCAN 0"}, "*2\r\n$1\r\n0\r\n*1\r\n$3\r\nfoo\r\n"},
+		{"scan_match", []string{"MSET foo 1 bar 2", "SCAN 0 MATCH b*"}, "*2\r\n$1\r\n0\r\n*1\r\n$3\r\nbar\r\n"},
+		{"scan_type", []string{"SET foo 1", "RPUSH bar x", "SCAN 0 TYPE list"}, "*2\r\n$1\r\n0\r\n*1\r\n$3\r\nbar\r\n"},
+		{"scan_unknown_type", []string{"SCAN 0 TYPE foo"}, "-ERR unknown type name 'foo'\r\n"},
+		{"scan_count_zero", []string{"SCAN 0 COUNT 0"}, "-ERR syntax error\r\n"},
+		{"scan_count_not_integer", []string{"SCAN 0 COUNT x"}, "-ERR value is not an integer or out of range\r\n"},
+		{"scan_missing_value", []string{"SCAN 0 MATCH"}, "-ERR syntax error\r\n"},
+		{"scan_bad_cursor", []string{"SCAN -1"}, "-ERR invalid cursor\r\n"},
+		{"hscan", []string{"HSET h f v", "HSCAN h 0"}, "*2\r\n$1\r\n0\r\n*2\r\n$1\r\nf\r\n$1\r\nv\r\n"},
+		{"sscan_intset", []string{"SADD s 3 1 2", "SSCAN s 0 MATCH [12]"}, "*2\r\n$1\r\n0\r\n*2\r\n$1\r\n1\r\n$1\r\n2\r\n"},
+		{"zscan", []string{"ZADD z 1.5 m", "ZSCAN z 0"}, "*2\r\n$1\r\n0\r\n*2\r\n$1\r\nm\r\n$3\r\n1.5\r\n"},
+		{"zscan_large_score", []string{"ZADD z 1e6 m", "ZSCAN z 0"}, "*2\r\n$1\r\n0\r\n*2\r\n$1\r\nm\r\n$7\r\n1000000\r\n"},
+		{"hscan_missing", []string{"HSCAN h 0"}, "*2\r\n$1\r\n0\r\n*0\r\n"},
+		{"hscan_wrong_type", []string{"SET h v", "HSCAN h 0"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"sscan_type_option", []string{"SADD s a", "SSCAN s 0 TYPE set"}, "-ERR syntax error\r\n"},
+	})
+}
+
+// TestScanIteration checks that a full SCAN iteration, one COUNT at a
+// time, returns every key.
+func TestScanIteration(t *testing.T) {
+	c := newTestClient()
+	for i := 0; i < 1000; i++ {
+		run(t, c, "SET key:"+strconv.Itoa(i)+" v")
+	}
+
+	seen := map[string]bool{}
+	cursor := "0"
+	for {
+		reply := handleCommand(c, []string{"SCAN", cursor, "COUNT", "7"}).(*respser.Array)
+		elems := *reply.Elements
+		cursor = *elems[0].(*respser.BulkString).S
+		for _, key := range *elems[1].(*respser.Array).Elements {
+			seen[*key.(*respser.BulkString).S] = true
+		}
+		if cursor == "0" {
+			break
+		}
+	}
+	if len(seen) != 1000 {
+		t.Errorf("Expected 1000 keys, Got %d", len(seen))
+	}
+}
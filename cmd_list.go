@@ -0,0 +1,570 @@
+package main
+
+import (
+	"math"
+	"strings"
+
+	"gored/keyspace"
+	"gored/respser"
+)
+
+func init() {
+	registerCommand("LPUSH", lpushCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("RPUSH", rpushCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("LPUSHX", lpushxCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("RPUSHX", rpushxCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("LPOP", lpopCommand, -2, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("RPOP", rpopCommand, -2, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("LLEN", llenCommand, 2, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("LRANGE", lrangeCommand, 4, flagReadonly, 1, 1, 1)
+	registerCommand("LINDEX", lindexCommand, 3, flagReadonly, 1, 1, 1)
+	registerCommand("LSET", lsetCommand, 4, flagWrite, 1, 1, 1)
+	registerCommand("LREM", lremCommand, 4, flagWrite, 1, 1, 1)
+	registerCommand("LTRIM", ltrimCommand, 4, flagWrite, 1, 1, 1)
+	registerCommand("LINSERT", linsertCommand, 5, flagWrite, 1, 1, 1)
+	registerCommand("LPOS", lposCommand, -3, flagReadonly, 1, 1, 1)
+	registerCommand("LMOVE", lmoveCommand, 5, flagWrite, 1, 2, 1)
+	registerCommand("RPOPLPUSH", rpoplpushCommand, 3, flagWrite, 1, 2, 1)
+	// The keys of LMPOP and BLMPOP are counted by their numkeys argument,
+	// so everything after it is locked to be safe.
+	registerCommand("LMPOP", lmpopCommand, -4, flagWrite, 2, -1, 1)
+	registerCommand("BLPOP", blpopCommand, -3, flagWrite|flagBlocking, 1, -2, 1)
+	registerCommand("BRPOP", brpopCommand, -3, flagWrite|flagBlocking, 1, -2, 1)
+	registerCommand("BLMOVE", blmoveCommand, 6, flagWrite|flagBlocking, 1, 2, 1)
+	registerCommand("BRPOPLPUSH", brpoplpushCommand, 4, flagWrite|flagBlocking, 1, 2, 1)
+	registerCommand("BLMPOP", blmpopCommand, -5, flagWrite|flagBlocking, 3, -1, 1)
+}
+
+func lpushCommand(c *client, args []string) respser.RespEncoder {
+	return pushGeneric(c, args, true, false)
+}
+
+func rpushCommand(c *client, args []string) respser.RespEncoder {
+	return pushGeneric(c, args, false, false)
+}
+
+func lpushxCommand(c *client, args []string) respser.RespEncoder {
+	return pushGeneric(c, args, true, true)
+}
+
+func rpushxCommand(c *client, args []string) respser.RespEncoder {
+	return pushGeneric(c, args, false, true)
+}
+
+// pushGeneric pushes the elements one after the other at the head or tail
+// of the list, creating it unless xx is set, and replies with its length.
+func pushGeneric(c *client, args []string, left, xx bool) respser.RespEncoder {
+	key := args[1]
+	l, ok, err := c.db.GetList(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		if xx {
+			return &respser.Integer{N: 0}
+		}
+		l = keyspace.NewList()
+		c.db.Set(key, l)
+	}
+	for _, v := range args[2:] {
+		listPush(l, left, v)
+	}
+	return &respser.Integer{N: int64(l.Len())}
+}
+
+func lpopCommand(c *client, args []string) respser.RespEncoder {
+	return popGeneric(c, args, true)
+}
+
+func rpopCommand(c *client, args []string) respser.RespEncoder {
+	return popGeneric(c, args, false)
+}
+
+// popGeneric implements LPOP and RPOP key [count]. Without a count it
+// replies with a single element, with one it replies with an array.
+func popGeneric(c *client, args []string, left bool) respser.RespEncoder {
+	if len(args) > 3 {
+		return wrongArityReply(args[0])
+	}
+	count := int64(-1)
+	if len(args) == 3 {
+		n, ok := parseInt64(args[2])
+		if !ok || n < 0 {
+			return errorReply("ERR value is out of range, must be positive")
+		}
+		count = n
+	}
+
+	key := args[1]
+	l, ok, err := c.db.GetList(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		if count >= 0 {
+			return &respser.Array{}
+		}
+		return &respser.BulkString{}
+	}
+	if count < 0 {
+		v, _ := listPop(l, left)
+		deleteIfEmpty(c, key, l)
+		return respser.NewBulkString(v)
+	}
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	for ; count > 0 && l.Len() > 0; count-- {
+		v, _ := listPop(l, left)
+		reply.AddElement(respser.NewBulkString(v))
+	}
+	deleteIfEmpty(c, key, l)
+	return reply
+}
+
+func llenCommand(c *client, args []string) respser.RespEncoder {
+	l, _, err := c.db.GetList(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if l == nil {
+		return &respser.Integer{N: 0}
+	}
+	return &respser.Integer{N: int64(l.Len())}
+}
+
+func lrangeCommand(c *client, args []string) respser.RespEncoder {
+	start, ok1 := parseInt64(args[2])
+	stop, ok2 := parseInt64(args[3])
+	if !ok1 || !ok2 {
+		return errorReply(errNotInteger)
+	}
+	l, ok, err := c.db.GetList(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	if !ok {
+		return reply
+	}
+	first, last, ok := listRange(l, start, stop)
+	if ok {
+		l.Range(first, last, func(v string) {
+			reply.AddElement(respser.NewBulkString(v))
+		})
+	}
+	return reply
+}
+
+func lindexCommand(c *client, args []string) respser.RespEncoder {
+	i, ok := parseInt64(args[2])
+	if !ok {
+		return errorReply(errNotInteger)
+	}
+	l, ok, err := c.db.GetList(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.BulkString{}
+	}
+	v, ok := l.Index(int(i))
+	if !ok {
+		return &respser.BulkString{}
+	}
+	return respser.NewBulkString(v)
+}
+
+func lsetCommand(c *client, args []string) respser.RespEncoder {
+	i, ok := parseInt64(args[2])
+	if !ok {
+		return errorReply(errNotInteger)
+	}
+	l, ok, err := c.db.GetList(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return errorReply("ERR no such key")
+	}
+	if !l.Set(int(i), args[3]) {
+		return errorReply("ERR index out of range")
+	}
+	return &respser.SimpleString{S: "OK"}
+}
+
+func lremCommand(c *client, args []string) respser.RespEncoder {
+	count, ok := parseInt64(args[2])
+	if !ok {
+		return errorReply(errNotInteger)
+	}
+	key := args[1]
+	l, ok, err := c.db.GetList(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	removed := l.Remove(args[3], int(count))
+	deleteIfEmpty(c, key, l)
+	return &respser.Integer{N: int64(removed)}
+}
+
+func ltrimCommand(c *client, args []string) respser.RespEncoder {
+	start, ok1 := parseInt64(args[2])
+	stop, ok2 := parseInt64(args[3])
+	if !ok1 || !ok2 {
+		return errorReply(errNotInteger)
+	}
+	key := args[1]
+	l, ok, err := c.db.GetList(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.SimpleString{S: "OK"}
+	}
+	first, last, ok := listRange(l, start, stop)
+	if !ok {
+		first, last = 1, 0
+	}
+	l.Trim(first, last)
+	deleteIfEmpty(c, key, l)
+	return &respser.SimpleString{S: "OK"}
+}
+
+// linsertCommand implements LINSERT key BEFORE|AFTER pivot element.
+func linsertCommand(c *client, args []string) respser.RespEncoder {
+	var after bool
+	switch strings.ToUpper(args[2]) {
+	case "BEFORE":
+	case "AFTER":
+		after = true
+	default:
+		return errorReply(errSyntax)
+	}
+	l, ok, err := c.db.GetList(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	if !l.Insert(args[3], args[4], after) {
+		return &respser.Integer{N: -1}
+	}
+	return &respser.Integer{N: int64(l.Len())}
+}
+
+// lposCommand implements
+// LPOS key element [RANK rank] [COUNT num-matches] [MAXLEN len].
+// RANK skips to the rank-th match, searching from the tail if it is
+// negative, COUNT asks for an array of that many matches, or all of them if
+// it is 0, and MAXLEN limits how many elements are compared.
+func lposCommand(c *client, args []string) respser.RespEncoder {
+	rank, count, maxlen := int64(1), int64(-1), int64(0)
+	for i := 3; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		if i+1 == len(args) {
+			return errorReply(errSyntax)
+		}
+		i++
+		n, ok := parseInt64(args[i])
+		switch opt {
+		case "RANK":
+			if !ok {
+				return errorReply(errNotInteger)
+			}
+			if n == math.MinInt64 {
+				return errorReply("ERR value is out of range, value must between %d and %d", -math.MaxInt64, math.MaxInt64)
+			}
+			if n == 0 {
+				return errorReply("ERR RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list")
+			}
+			rank = n
+		case "COUNT":
+			if !ok || n < 0 {
+				return errorReply("ERR COUNT can't be negative")
+			}
+			count = n
+		case "MAXLEN":
+			if !ok || n < 0 {
+				return errorReply("ERR MAXLEN can't be negative")
+			}
+			maxlen = n
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+
+	l, ok, err := c.db.GetList(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	if !ok {
+		if count >= 0 {
+			return reply
+		}
+		return &respser.BulkString{}
+	}
+
+	skip := max(rank, -rank) - 1
+	compared := int64(0)
+	l.Each(rank < 0, func(i int, v string) bool {
+		if maxlen > 0 && compared == maxlen {
+			return false
+		}
+		compared++
+		if v != args[2] {
+			return true
+		}
+		if skip > 0 {
+			skip--
+			return true
+		}
+		reply.AddElement(&respser.Integer{N: int64(i)})
+		return count == 0 || int64(len(*reply.Elements)) < max(count, 1)
+	})
+	if count >= 0 {
+		return reply
+	}
+	if len(*reply.Elements) == 0 {
+		return &respser.BulkString{}
+	}
+	return (*reply.Elements)[0]
+}
+
+// lmoveCommand implements LMOVE source destination LEFT|RIGHT LEFT|RIGHT.
+func lmoveCommand(c *client, args []string) respser.RespEncoder {
+	from, ok1 := parseWhere(args[3])
+	to, ok2 := parseWhere(args[4])
+	if !ok1 || !ok2 {
+		return errorReply(errSyntax)
+	}
+	return lmoveGeneric(c, args[1], args[2], from, to)
+}
+
+func rpoplpushCommand(c *client, args []string) respser.RespEncoder {
+	return lmoveGeneric(c, args[1], args[2], false, true)
+}
+
+// lmoveGeneric pops an element from one end of the source list and pushes
+// it at one end of the destination list, and replies with it.
+func lmoveGeneric(c *client, src, dst string, from, to bool) respser.RespEncoder {
+	l, ok, err := c.db.GetList(src)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.BulkString{}
+	}
+	// The destination is checked first, so that nothing is popped from the
+	// source if it cannot be pushed.
+	dl, ok, err := c.db.GetList(dst)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		dl = keyspace.NewList()
+		c.db.Set(dst, dl)
+	}
+	// Pushing before deleting an emptied source keeps a list rotated onto
+	// itself alive.
+	v, _ := listPop(l, from)
+	listPush(dl, to, v)
+	deleteIfEmpty(c, src, l)
+	return respser.NewBulkString(v)
+}
+
+func lmpopCommand(c *client, args []string) respser.RespEncoder {
+	keys, left, count, errReply := parseMpopArgs(args, 1)
+	if errReply != nil {
+		return errReply
+	}
+	if reply := mpopGeneric(c, keys, left, count); reply != nil {
+		return reply
+	}
+	return &respser.Array{}
+}
+
+// mpopGeneric pops up to count elements from the first of keys that holds
+// a list, and replies with the key and the elements, or with nil if every
+// key is empty.
+func mpopGeneric(c *client, keys []string, left bool, count int64) respser.RespEncoder {
+	for _, key := range keys {
+		l, ok, err := c.db.GetList(key)
+		if err != nil {
+			return errorReply(errWrongType)
+		}
+		if !ok {
+			continue
+		}
+		elems := &respser.Array{Elements: &[]respser.RespEncoder{}}
+		for ; count > 0 && l.Len() > 0; count-- {
+			v, _ := listPop(l, left)
+			elems.AddElement(respser.NewBulkString(v))
+		}
+		deleteIfEmpty(c, key, l)
+		return &respser.Array{Elements: &[]respser.RespEncoder{respser.NewBulkString(key), elems}}
+	}
+	return nil
+}
+
+// parseMpopArgs parses the arguments of LMPOP and BLMPOP from numkeys on,
+// which comes at args[numkeysAt]: numkeys key [key ...] LEFT|RIGHT [COUNT count].
+func parseMpopArgs(args []string, numkeysAt int) (keys []string, left bool, count int64, errReply *respser.ErrorString) {
+	numkeys, ok := parseInt64(args[numkeysAt])
+	if !ok || numkeys <= 0 {
+		return nil, false, 0, errorReply("ERR numkeys should be greater than 0")
+	}
+	rest := args[numkeysAt+1:]
+	if numkeys > int64(len(rest)-1) {
+		return nil, false, 0, errorReply("ERR Number of keys can't be greater than number of args")
+	}
+	keys, rest = rest[:numkeys], rest[numkeys:]
+	left, ok = parseWhere(rest[0])
+	if !ok {
+		return nil, false, 0, errorReply(errSyntax)
+	}
+	count = 1
+	switch {
+	case len(rest) == 1:
+	case len(rest) == 3 && strings.EqualFold(rest[1], "COUNT"):
+		count, ok = parseInt64(rest[2])
+		if !ok || count <= 0 {
+			return nil, false, 0, errorReply("ERR count should be greater than 0")
+		}
+	default:
+		return nil, false, 0, errorReply(errSyntax)
+	}
+	return keys, left, count, nil
+}
+
+func blpopCommand(c *client, args []string) respser.RespEncoder {
+	return blockingPopGeneric(c, args, true)
+}
+
+func brpopCommand(c *client, args []string) respser.RespEncoder {
+	return blockingPopGeneric(c, args, false)
+}
+
+// blockingPopGeneric implements BLPOP and BRPOP key [key ...] timeout. It
+// pops from the first of the keys that holds a list and replies with the
+// key and the element, or blocks until one of them does.
+func blockingPopGeneric(c *client, args []string, left bool) respser.RespEncoder {
+	timeout, errReply := parseTimeout(args[len(args)-1])
+	if errReply != nil {
+		return errReply
+	}
+	keys := args[1 : len(args)-1]
+	for _, key := range keys {
+		l, ok, err := c.db.GetList(key)
+		if err != nil {
+			return errorReply(errWrongType)
+		}
+		if !ok {
+			continue
+		}
+		v, _ := listPop(l, left)
+		deleteIfEmpty(c, key, l)
+		return &respser.Array{Elements: &[]respser.RespEncoder{respser.NewBulkString(key), respser.NewBulkString(v)}}
+	}
+	return c.block(keys, timeout, &respser.Array{})
+}
+
+// blmoveCommand implements
+// BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout.
+func blmoveCommand(c *client, args []string) respser.RespEncoder {
+	from, ok1 := parseWhere(args[3])
+	to, ok2 := parseWhere(args[4])
+	if !ok1 || !ok2 {
+		return errorReply(errSyntax)
+	}
+	return blmoveGeneric(c, args[1], args[2], from, to, args[5])
+}
+
+func brpoplpushCommand(c *client, args []string) respser.RespEncoder {
+	return blmoveGeneric(c, args[1], args[2], false, true, args[3])
+}
+
+// blmoveGeneric is lmoveGeneric, blocking while the source is empty.
+func blmoveGeneric(c *client, src, dst string, from, to bool, timeoutArg string) respser.RespEncoder {
+	timeout, errReply := parseTimeout(timeoutArg)
+	if errReply != nil {
+		return errReply
+	}
+	if _, ok, err := c.db.GetList(src); ok || err != nil {
+		return lmoveGeneric(c, src, dst, from, to)
+	}
+	return c.block([]string{src}, timeout, &respser.BulkString{})
+}
+
+// blmpopCommand implements
+// BLMPOP timeout numkeys key [key ...] LEFT|RIGHT [COUNT count].
+func blmpopCommand(c *client, args []string) respser.RespEncoder {
+	keys, left, count, errReply := parseMpopArgs(args, 2)
+	if errReply != nil {
+		return errReply
+	}
+	timeout, errReply := parseTimeout(args[1])
+	if errReply != nil {
+		return errReply
+	}
+	if reply := mpopGeneric(c, keys, left, count); reply != nil {
+		return reply
+	}
+	return c.block(keys, timeout, &respser.Array{})
+}
+
+// listRange turns the start and stop arguments of LRANGE or LTRIM, which
+// count from the tail when negative, into the indexes of an inclusive
+// range within l, and reports whether the range holds any elements.
+func listRange(l *keyspace.List, start, stop int64) (int, int, bool) {
+	n := int64(l.Len())
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	start = max(start, 0)
+	stop = min(stop, n-1)
+	if start > stop {
+		return 0, 0, false
+	}
+	return int(start), int(stop), true
+}
+
+// parseWhere parses LEFT or RIGHT, returning whether it is LEFT.
+func parseWhere(s string) (left bool, ok bool) {
+	switch strings.ToUpper(s) {
+	case "LEFT":
+		return true, true
+	case "RIGHT":
+		return false, true
+	}
+	return false, false
+}
+
+func listPush(l *keyspace.List, left bool, v string) {
+	if left {
+		l.PushFront(v)
+	} else {
+		l.PushBack(v)
+	}
+}
+
+func listPop(l *keyspace.List, left bool) (string, bool) {
+	if left {
+		return l.PopFront()
+	}
+	return l.PopBack()
+}
+
+// deleteIfEmpty deletes key if the list stored at it is empty, as lists
+// only exist while they hold elements.
+func deleteIfEmpty(c *client, key string, l *keyspace.List) {
+	if l.Len() == 0 {
+		c.db.Delete(key)
+	}
+}
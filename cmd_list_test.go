@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestListCommands(t *testing.T) {
+	runCases(t, []commandCase{
+		{"lpush_order", []string{"LPUSH l a b c", "LRANGE l 0 -1"}, "*3\r\n$1\r\nc\r\n$1\r\nb\r\n$1\r\na\r\n"},
+		{"rpush_length", []string{"RPUSH l a", "RPUSH l b c"}, ":3\r\n"},
+		{"lpushx_missing", []string{"LPUSHX l a", "EXISTS l"}, ":0\r\n"},
+		{"rpushx_existing", []string{"RPUSH l a", "RPUSHX l b"}, ":2\r\n"},
+		{"push_wrong_type", []string{"SET l v", "RPUSH l a"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"lpop", []string{"RPUSH l a b", "LPOP l"}, "$1\r\na\r\n"},
+		{"rpop_count", []string{"RPUSH l a b c", "RPOP l 2"}, "*2\r\n$1\r\nc\r\n$1\r\nb\r\n"},
+		{"lpop_count_zero", []string{"RPUSH l a", "LPOP l 0"}, "*0\r\n"},
+		{"lpop_missing", []string{"LPOP l"}, "$-1\r\n"},
+		{"lpop_count_missing", []string{"LPOP l 2"}, "*-1\r\n"},
+		{"lpop_negative_count", []string{"RPUSH l a", "LPOP l -1"}, "-ERR value is out of range, must be positive\r\n"},
+		{"pop_last_deletes", []string{"RPUSH l a", "LPOP l", "EXISTS l"}, ":0\r\n"},
+		{"llen", []string{"RPUSH l a b", "LLEN l"}, ":2\r\n"},
+		{"lrange_negative", []string{"RPUSH l a b c", "LRANGE l -2 100"}, "*2\r\n$1\r\nb\r\n$1\r\nc\r\n"},
+		{"lrange_empty", []string{"RPUSH l a b c", "LRANGE l 2 1"}, "*0\r\n"},
+		{"lindex", []string{"RPUSH l a b c", "LINDEX l -1"}, "$1\r\nc\r\n"},
+		{"lindex_out_of_range", []string{"RPUSH l a", "LINDEX l 5"}, "$-1\r\n"},
+		{"lset", []string{"RPUSH l a b", "LSET l -1 x", "LRANGE l 0 -1"}, "*2\r\n$1\r\na\r\n$1\r\nx\r\n"},
+		{"lset_missing", []string{"LSET l 0 x"}, "-ERR no such key\r\n"},
+		{"lset_out_of_range", []string{"RPUSH l a", "LSET l 1 x"}, "-ERR index out of range\r\n"},
+		{"lrem_head", []string{"RPUSH l a b a a", "LREM l 2 a", "LRANGE l 0 -1"}, "*2\r\n$1\r\nb\r\n$1\r\na\r\n"},
+		{"lrem_tail", []string{"RPUSH l a b a a", "LREM l -2 a", "LRANGE l 0 -1"}, "*2\r\n$1\r\na\r\n$1\r\nb\r\n"},
+		{"lrem_all", []string{"RPUSH l a b a a", "LREM l 0 a"}, ":3\r\n"},
+		{"ltrim", []string{"RPUSH l a b c d", "LTRIM l 1 -2", "LRANGE l 0 -1"}, "*2\r\n$1\r\nb\r\n$1\r\nc\r\n"},
+		{"ltrim_all", []string{"RPUSH l a b", "LTRIM l 5 10", "EXISTS l"}, ":0\r\n"},
+		{"linsert_before", []string{"RPUSH l a c", "LINSERT l BEFORE c b", "LRANGE l 0 -1"}, "*3\r\n$1\r\na\r\n$1\r\nb\r\n$1\r\nc\r\n"},
+		{"linsert_no_pivot", []string{"RPUSH l a", "LINSERT l AFTER x b"}, ":-1\r\n"},
+		{"linsert_missing", []string{"LINSERT l AFTER x b"}, ":0\r\n"},
+		{"linsert_bad_where", []string{"RPUSH l a", "LINSERT l MIDDLE a b"}, "-ERR syntax error\r\n"},
+		{"lpos", []string{"RPUSH l a b c 1 2 3 c c", "LPOS l c"}, ":2\r\n"},
+		{"lpos_rank", []string{"RPUSH l a b c 1 2 3 c c", "LPOS l c RANK -1"}, ":7\r\n"},
+		{"lpos_count", []string{"RPUSH l a b c 1 2 3 c c", "LPOS l c COUNT 0"}, "*3\r\n:2\r\n:6\r\n:7\r\n"},
+		{"lpos_maxlen", []string{"RPUSH l a b c 1 2 3 c c", "LPOS l c COUNT 0 MAXLEN 5"}, "*1\r\n:2\r\n"},
+		{"lpos_not_found", []string{"RPUSH l a", "LPOS l x"}, "$-1\r\n"},
+		{"lpos_rank_zero", []string{"RPUSH l a", "LPOS l a RANK 0"}, "-ERR RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list\r\n"},
+		{"lpos_negative_count", []string{"RPUSH l a", "LPOS l a COUNT -1"}, "-ERR COUNT can't be negative\r\n"},
+		{"lmove", []string{"RPUSH a 1 2", "RPUSH b x", "LMOVE a b RIGHT LEFT", "LRANGE b 0 -1"}, "*2\r\n$1\r\n2\r\n$1\r\nx\r\n"},
+		{"lmove_same_list", []string{"RPUSH a 1 2 3", "LMOVE a a LEFT RIGHT", "LRANGE a 0 -1"}, "*3\r\n$1\r\n2\r\n$1\r\n3\r\n$1\r\n1\r\n"},
+		{"lmove_wrong_type_dst", []string{"RPUSH a 1", "SET b v", "LMOVE a b LEFT LEFT"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"rpoplpush", []string{"RPUSH a 1 2", "RPOPLPUSH a b", "LRANGE b 0 -1"}, "*1\r\n$1\r\n2\r\n"},
+		{"lmpop", []string{"RPUSH b 1 2 3", "LMPOP 2 a b RIGHT COUNT 2"}, "*2\r\n$1\r\nb\r\n*2\r\n$1\r\n3\r\n$1\r\n2\r\n"},
+		{"lmpop_empty", []string{"LMPOP 1 a LEFT"}, "*-1\r\n"},
+		{"lmpop_numkeys_zero", []string{"LMPOP 0 a LEFT"}, "-ERR numkeys should be greater than 0\r\n"},
+		{"lmpop_too_many_keys", []string{"LMPOP 3 a LEFT"}, "-ERR Number of keys can't be greater than number of args\r\n"},
+		{"lmpop_count_zero", []string{"LMPOP 1 a LEFT COUNT 0"}, "-ERR count should be greater than 0\r\n"},
+	})
+}
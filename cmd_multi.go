@@ -0,0 +1,58 @@
+package main
+
+import (
+	"gored/respser"
+)
+
+func init() {
+	registerCommand("MULTI", multiCommand, 1, flagFast|flagNoQueue, 0, 0, 0)
+	registerCommand("EXEC", execCommand, 1, flagNoQueue, 0, 0, 0)
+	registerCommand("DISCARD", discardCommand, 1, flagFast|flagNoQueue, 0, 0, 0)
+}
+
+func multiCommand(c *client, args []string) respser.RespEncoder {
+	if c.inMulti {
+		return errorReply("ERR MULTI calls can not be nested")
+	}
+	c.inMulti = true
+	return &respser.SimpleString{S: "OK"}
+}
+
+// execCommand runs the commands queued since MULTI, holding the locks all
+// of them need for the whole time, so that no other client sees or changes
+// the keys in between.
+func execCommand(c *client, args []string) respser.RespEncoder {
+	if !c.inMulti {
+		return errorReply("ERR EXEC without MULTI")
+	}
+	queued, failed := c.queued, c.multiError
+	c.discardMulti()
+	if failed {
+		return errorReply("EXECABORT Transaction discarded because of previous errors.")
+	}
+
+	unlock := lockKeys(c.db, queued)
+	defer unlock()
+	c.inExec = true
+	defer func() { c.inExec = false }()
+
+	replies := make([]respser.RespEncoder, 0, len(queued))
+	for _, args := range queued {
+		replies = append(replies, lookupCommand(args[0]).handler(c, args))
+	}
+	return &respser.Array{Elements: &replies}
+}
+
+func discardCommand(c *client, args []string) respser.RespEncoder {
+	if !c.inMulti {
+		return errorReply("ERR DISCARD without MULTI")
+	}
+	c.discardMulti()
+	return &respser.SimpleString{S: "OK"}
+}
+
+func (c *client) discardMulti() {
+	c.inMulti = false
+	c.queued = nil
+	c.multiError = false
+}
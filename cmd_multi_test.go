@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestMulti(t *testing.T) {
+	runCases(t, []commandCase{
+		{"queued", []string{"MULTI", "SET k v"}, "+QUEUED\r\n"},
+		{"exec", []string{"MULTI", "SET k v", "GET k", "EXEC"}, "*2\r\n+OK\r\n$1\r\nv\r\n"},
+		{"exec_empty", []string{"MULTI", "EXEC"}, "*0\r\n"},
+		{"exec_error_reply", []string{"SET k v", "MULTI", "LPUSH k a", "GET k", "EXEC"},
+			"*2\r\n-WRONGTYPE Operation against a key holding the wrong kind of value\r\n$1\r\nv\r\n"},
+		{"exec_without_multi", []string{"EXEC"}, "-ERR EXEC without MULTI\r\n"},
+		{"nested_multi", []string{"MULTI", "MULTI"}, "-ERR MULTI calls can not be nested\r\n"},
+		{"discard", []string{"MULTI", "SET k v", "DISCARD", "EXISTS k"}, ":0\r\n"},
+		{"discard_without_multi", []string{"DISCARD"}, "-ERR DISCARD without MULTI\r\n"},
+		{"execabort", []string{"MULTI", "NOSUCH", "SET k v", "EXEC"}, "-EXECABORT Transaction discarded because of previous errors.\r\n"},
+		{"execabort_runs_nothing", []string{"MULTI", "SET k v", "GET", "EXEC", "EXISTS k"}, ":0\r\n"},
+	})
+}
+
+func TestBlockingCommands(t *testing.T) {
+	runCases(t, []commandCase{
+		{"blpop_ready", []string{"RPUSH b x", "BLPOP a b 0"}, "*2\r\n$1\r\nb\r\n$1\r\nx\r\n"},
+		{"brpop_ready", []string{"RPUSH a x y", "BRPOP a 0"}, "*2\r\n$1\r\na\r\n$1\r\ny\r\n"},
+		{"blmove_ready", []string{"RPUSH a x", "BLMOVE a b LEFT RIGHT 0", "LRANGE b 0 -1"}, "*1\r\n$1\r\nx\r\n"},
+		{"brpoplpush_ready", []string{"RPUSH a x", "BRPOPLPUSH a b 0"}, "$1\r\nx\r\n"},
+		{"blmpop_ready", []string{"RPUSH b x", "BLMPOP 0 2 a b LEFT"}, "*2\r\n$1\r\nb\r\n*1\r\n$1\r\nx\r\n"},
+		{"blpop_in_exec", []string{"MULTI", "BLPOP a 0", "EXEC"}, "*1\r\n*-1\r\n"},
+		{"brpoplpush_in_exec", []string{"MULTI", "BRPOPLPUSH a b 0", "EXEC"}, "*1\r\n$-1\r\n"},
+		{"blpop_wrong_type", []string{"SET a v", "BLPOP a 0"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"timeout_not_float", []string{"BLPOP a x"}, "-ERR timeout is not a float or out of range\r\n"},
+		{"timeout_negative", []string{"BLPOP a -1"}, "-ERR timeout is negative\r\n"},
+		{"timeout_overflow", []string{"BLPOP a 1e400"}, "-ERR timeout is not a float or out of range\r\n"},
+	})
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"gored/keyspace"
+	"gored/respser"
+)
+
+func init() {
+	registerCommand("SADD", saddCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("SREM", sremCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("SISMEMBER", sismemberCommand, 3, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("SMISMEMBER", smismemberCommand, -3, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("SMEMBERS", smembersCommand, 2, flagReadonly, 1, 1, 1)
+	registerCommand("SCARD", scardCommand, 2, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("SPOP", spopCommand, -2, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("SRANDMEMBER", srandmemberCommand, -2, flagReadonly, 1, 1, 1)
+	registerCommand("SMOVE", smoveCommand, 4, flagWrite|flagFast, 1, 2, 1)
+	registerCommand("SINTER", sinterCommand, -2, flagReadonly, 1, -1, 1)
+	registerCommand("SINTERSTORE", sinterstoreCommand, -3, flagWrite, 1, -1, 1)
+	registerCommand("SUNION", sunionCommand, -2, flagReadonly, 1, -1, 1)
+	registerCommand("SUNIONSTORE", sunionstoreCommand, -3, flagWrite, 1, -1, 1)
+	registerCommand("SDIFF", sdiffCommand, -2, flagReadonly, 1, -1, 1)
+	registerCommand("SDIFFSTORE", sdiffstoreCommand, -3, flagWrite, 1, -1, 1)
+	registerCommand("SINTERCARD", sintercardCommand, -3, flagReadonly, 2, -1, 1)
+}
+
+func saddCommand(c *client, args []string) respser.RespEncoder {
+	key := args[1]
+	s, ok, err := c.db.GetSet(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		s = keyspace.NewSet()
+		c.db.Set(key, s)
+	}
+	var added int64
+	for _, m := range args[2:] {
+		if s.Add(m) {
+			added++
+		}
+	}
+	return &respser.Integer{N: added}
+}
+
+func sremCommand(c *client, args []string) respser.RespEncoder {
+	key := args[1]
+	s, ok, err := c.db.GetSet(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	var removed int64
+	for _, m := range args[2:] {
+		if s.Remove(m) {
+			removed++
+		}
+	}
+	deleteIfEmptySet(c, key, s)
+	return &respser.Integer{N: removed}
+}
+
+func sismemberCommand(c *client, args []string) respser.RespEncoder {
+	s, ok, err := c.db.GetSet(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok || !s.Has(args[2]) {
+		return &respser.Integer{N: 0}
+	}
+	return &respser.Integer{N: 1}
+}
+
+func smismemberCommand(c *client, args []string) respser.RespEncoder {
+	s, ok, err := c.db.GetSet(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	for _, m := range args[2:] {
+		if ok && s.Has(m) {
+			reply.AddElement(&respser.Integer{N: 1})
+		} else {
+			reply.AddElement(&respser.Integer{N: 0})
+		}
+	}
+	return reply
+}
+
+func smembersCommand(c *client, args []string) respser.RespEncoder {
+	s, ok, err := c.db.GetSet(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Set{Elements: []respser.RespEncoder{}}
+	}
+	return membersReply(s)
+}
+
+func scardCommand(c *client, args []string) respser.RespEncoder {
+	s, ok, err := c.db.GetSet(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	return &respser.Integer{N: int64(s.Len())}
+}
+
+// spopCommand implements SPOP key [count], which removes and replies with
+// one random member, or with a set of up to count of them.
+func spopCommand(c *client, args []string) respser.RespEncoder {
+	if len(args) > 3 {
+		return errorReply(errSyntax)
+	}
+	key := args[1]
+	count := int64(-1)
+	if len(args) == 3 {
+		var ok bool
+		count, ok = parseInt64(args[2])
+		if !ok || count < 0 {
+			return errorReply("ERR value is out of range, must be positive")
+		}
+	}
+	s, ok, err := c.db.GetSet(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if count < 0 {
+		if !ok {
+			return &respser.BulkString{}
+		}
+		m, _ := s.Random()
+		s.Remove(m)
+		deleteIfEmptySet(c, key, s)
+		return respser.NewBulkString(m)
+	}
+
+	if !ok {
+		return &respser.Set{Elements: []respser.RespEncoder{}}
+	}
+	if count >= int64(s.Len()) {
+		c.db.Delete(key)
+		return membersReply(s)
+	}
+	reply := &respser.Set{Elements: make([]respser.RespEncoder, 0, count)}
+	for ; count > 0; count-- {
+		m, _ := s.Random()
+		s.Remove(m)
+		reply.AddElement(respser.NewBulkString(m))
+	}
+	return reply
+}
+
+// srandmemberCommand implements SRANDMEMBER key [count]. A positive count
+// asks for that many distinct members, or all of them if there are fewer,
+// and a negative one for -count members that may repeat.
+func srandmemberCommand(c *client, args []string) respser.RespEncoder {
+	if len(args) > 3 {
+		return errorReply(errSyntax)
+	}
+	s, ok, err := c.db.GetSet(args[1])
+	if len(args) == 2 {
+		if err != nil {
+			return errorReply(errWrongType)
+		}
+		if !ok {
+			return &respser.BulkString{}
+		}
+		m, _ := s.Random()
+		return respser.NewBulkString(m)
+	}
+
+	count, parsed := parseInt64(args[2])
+	if !parsed {
+		return errorReply(errNotInteger)
+	}
+	if count == math.MinInt64 {
+		return errorReply("ERR value is out of range")
+	}
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	if !ok {
+		return reply
+	}
+	if count < 0 {
+		for ; count < 0; count++ {
+			m, _ := s.Random()
+			reply.AddElement(respser.NewBulkString(m))
+		}
+		return reply
+	}
+	if count >= int64(s.Len()) {
+		s.Range(func(m string) bool {
+			reply.AddElement(respser.NewBulkString(m))
+			return true
+		})
+		return reply
+	}
+	// As in HRANDFIELD, most of the members are shuffled rather than
+	// picked at random until enough are distinct.
+	if count*3 > int64(s.Len()) {
+		ms := make([]string, 0, s.Len())
+		s.Range(func(m string) bool {
+			ms = append(ms, m)
+			return true
+		})
+		rand.Shuffle(len(ms), func(i, j int) { ms[i], ms[j] = ms[j], ms[i] })
+		for _, m := range ms[:count] {
+			reply.AddElement(respser.NewBulkString(m))
+		}
+		return reply
+	}
+	picked := map[string]bool{}
+	for int64(len(picked)) < count {
+		m, _ := s.Random()
+		if !picked[m] {
+			picked[m] = true
+			reply.AddElement(respser.NewBulkString(m))
+		}
+	}
+	return reply
+}
+
+func smoveCommand(c *client, args []string) respser.RespEncoder {
+	src, dst, m := args[1], args[2], args[3]
+	s, ok, err := c.db.GetSet(src)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	// As in Redis, a missing source is not an error whatever the
+	// destination holds.
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	d, dstOk, err := c.db.GetSet(dst)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !s.Has(m) {
+		return &respser.Integer{N: 0}
+	}
+	if src == dst {
+		return &respser.Integer{N: 1}
+	}
+	s.Remove(m)
+	deleteIfEmptySet(c, src, s)
+	if !dstOk {
+		d = keyspace.NewSet()
+		c.db.Set(dst, d)
+	}
+	d.Add(m)
+	return &respser.Integer{N: 1}
+}
+
+func sinterCommand(c *client, args []string) respser.RespEncoder {
+	return setOpGeneric(c, args, false, inter)
+}
+
+func sinterstoreCommand(c *client, args []string) respser.RespEncoder {
+	return setOpGeneric(c, args, true, inter)
+}
+
+func sunionCommand(c *client, args []string) respser.RespEncoder {
+	return setOpGeneric(c, args, false, union)
+}
+
+func sunionstoreCommand(c *client, args []string) respser.RespEncoder {
+	return setOpGeneric(c, args, true, union)
+}
+
+func sdiffCommand(c *client, args []string) respser.RespEncoder {
+	return setOpGeneric(c, args, false, diff)
+}
+
+func sdiffstoreCommand(c *client, args []string) respser.RespEncoder {
+	return setOpGeneric(c, args, true, diff)
+}
+
+// setOpGeneric implements SINTER, SUNION and SDIFF key [key ...], which
+// reply with the result of op on the sets at the keys, and their *STORE
+// variants destination key [key ...], which store it at destination
+// instead, replacing whatever was there, and reply with its size. Missing
+// keys count as empty sets.
+func setOpGeneric(c *client, args []string, store bool, op func(sets []*keyspace.Set) *keyspace.Set) respser.RespEncoder {
+	keys := args[1:]
+	if store {
+		keys = args[2:]
+	}
+	sets, err := readSets(c, keys)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	result := op(sets)
+	if !store {
+		return membersReply(result)
+	}
+	dst := args[1]
+	if result.Len() == 0 {
+		c.db.Delete(dst)
+	} else {
+		c.db.Set(dst, result)
+	}
+	return &respser.Integer{N: int64(result.Len())}
+}
+
+// sintercardCommand implements SINTERCARD numkeys key [key ...]
+// [LIMIT limit], which counts the members of the intersection, stopping at
+// limit unless it is 0.
+func sintercardCommand(c *client, args []string) respser.RespEncoder {
+	numkeys, ok := parseInt64(args[1])
+	if !ok || numkeys <= 0 {
+		return errorReply("ERR numkeys should be greater than 0")
+	}
+	rest := args[2:]
+	if numkeys > int64(len(rest)) {
+		return errorReply("ERR Number of keys can't be greater than number of args")
+	}
+	keys, rest := rest[:numkeys], rest[numkeys:]
+	var limit int64
+	switch {
+	case len(rest) == 0:
+	case len(rest) == 2 && strings.EqualFold(rest[0], "LIMIT"):
+		limit, ok = parseInt64(rest[1])
+		if !ok || limit < 0 {
+			return errorReply("ERR LIMIT can't be negative")
+		}
+	default:
+		return errorReply(errSyntax)
+	}
+
+	sets, err := readSets(c, keys)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	var n int64
+	eachInter(sets, func(string) bool {
+		n++
+		return limit == 0 || n < limit
+	})
+	return &respser.Integer{N: n}
+}
+
+// readSets returns the sets stored at keys, with nil for the missing ones.
+func readSets(c *client, keys []string) ([]*keyspace.Set, error) {
+	sets := make([]*keyspace.Set, len(keys))
+	for i, key := range keys {
+		s, _, err := c.db.GetSet(key)
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = s
+	}
+	return sets, nil
+}
+
+func inter(sets []*keyspace.Set) *keyspace.Set {
+	result := keyspace.NewSet()
+	eachInter(sets, func(m string) bool {
+		result.Add(m)
+		return true
+	})
+	return result
+}
+
+// eachInter calls fn for the members of the intersection of sets, a nil
+// one being empty, until fn returns false. It goes over the smallest set
+// and looks the members up in the others.
+func eachInter(sets []*keyspace.Set, fn func(m string) bool) {
+	if slices.Contains(sets, nil) {
+		return
+	}
+	sets = slices.Clone(sets)
+	slices.SortFunc(sets, func(a, b *keyspace.Set) int { return a.Len() - b.Len() })
+	sets[0].Range(func(m string) bool {
+		for _, s := range sets[1:] {
+			if !s.Has(m) {
+				return true
+			}
+		}
+		return fn(m)
+	})
+}
+
+func union(sets []*keyspace.Set) *keyspace.Set {
+	result := keyspace.NewSet()
+	for _, s := range sets {
+		if s == nil {
+			continue
+		}
+		s.Range(func(m string) bool {
+			result.Add(m)
+			return true
+		})
+	}
+	return result
+}
+
+// diff returns the members of the first set that are in none of the
+// others.
+func diff(sets []*keyspace.Set) *keyspace.Set {
+	result := keyspace.NewSet()
+	if sets[0] == nil {
+		return result
+	}
+	sets[0].Range(func(m string) bool {
+		for _, s := range sets[1:] {
+			if s != nil && s.Has(m) {
+				return true
+			}
+		}
+		result.Add(m)
+		return true
+	})
+	return result
+}
+
+// membersReply replies with all members of s, as a set for RESP3 clients.
+func membersReply(s *keyspace.Set) respser.RespEncoder {
+	reply := &respser.Set{Elements: make([]respser.RespEncoder, 0, s.Len())}
+	s.Range(func(m string) bool {
+		reply.AddElement(respser.NewBulkString(m))
+		return true
+	})
+	return reply
+}
+
+// deleteIfEmptySet deletes key if the set stored at it is empty, as sets
+// only exist while they have members.
+func deleteIfEmptySet(c *client, key string, s *keyspace.Set) {
+	if s.Len() == 0 {
+		c.db.Delete(key)
+	}
+}
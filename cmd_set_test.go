@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestSetCommands(t *testing.T) {
+	runCases(t, []commandCase{
+		{"sadd", []string{"SADD s 1 2", "SADD s 2 3 3"}, ":1\r\n"},
+		{"sadd_wrong_type", []string{"SET s v", "SADD s a"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"smembers_intset_sorted", []string{"SADD s 3 -1 2", "SMEMBERS s"}, "*3\r\n$2\r\n-1\r\n$1\r\n2\r\n$1\r\n3\r\n"},
+		{"smembers_missing", []string{"SMEMBERS s"}, "*0\r\n"},
+		{"srem", []string{"SADD s a b", "SREM s a c"}, ":1\r\n"},
+		{"srem_last_deletes", []string{"SADD s a", "SREM s a", "EXISTS s"}, ":0\r\n"},
+		{"sismember", []string{"SADD s 1", "SISMEMBER s 01"}, ":0\r\n"},
+		{"smismember", []string{"SADD s a", "SMISMEMBER s a b"}, "*2\r\n:1\r\n:0\r\n"},
+		{"scard", []string{"SADD s a b 1", "SCARD s"}, ":3\r\n"},
+		{"spop", []string{"SADD s a", "SPOP s"}, "$1\r\na\r\n"},
+		{"spop_count", []string{"SADD s 1 2", "SPOP s 5", "EXISTS s"}, ":0\r\n"},
+		{"spop_missing", []string{"SPOP s"}, "$-1\r\n"},
+		{"spop_negative", []string{"SADD s a", "SPOP s -1"}, "-ERR value is out of range, must be positive\r\n"},
+		{"srandmember", []string{"SADD s a", "SRANDMEMBER s"}, "$1\r\na\r\n"},
+		{"srandmember_negative", []string{"SADD s a", "SRANDMEMBER s -2"}, "*2\r\n$1\r\na\r\n$1\r\na\r\n"},
+		{"srandmember_count_missing", []string{"SRANDMEMBER s 2"}, "*0\r\n"},
+		{"smove", []string{"SADD a 1 2", "SMOVE a b 1", "SMEMBERS b"}, "*1\r\n$1\r\n1\r\n"},
+		{"smove_not_member", []string{"SADD a 1", "SMOVE a b 2"}, ":0\r\n"},
+		{"smove_same_set", []string{"SADD a 1", "SMOVE a a 1"}, ":1\r\n"},
+		{"smove_last_deletes", []string{"SADD a 1", "SMOVE a b 1", "EXISTS a"}, ":0\r\n"},
+		{"smove_missing_src_wrong_type_dst", []string{"SET b v", "SMOVE a b 1"}, ":0\r\n"},
+		{"smove_wrong_type_dst", []string{"SADD a 1", "SET b v", "SMOVE a b 1"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"smove_wrong_type_src", []string{"SET a v", "SMOVE a b 1"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"sinter", []string{"SADD a 1 2 3", "SADD b 2 3 4", "SINTER a b"}, "*2\r\n$1\r\n2\r\n$1\r\n3\r\n"},
+		{"sinter_missing", []string{"SADD a 1", "SINTER a b"}, "*0\r\n"},
+		{"sunion", []string{"SADD a 1 2", "SADD b 2 3", "SUNIONSTORE d a b", "SMEMBERS d"}, "*3\r\n$1\r\n1\r\n$1\r\n2\r\n$1\r\n3\r\n"},
+		{"sdiff", []string{"SADD a 1 2 3", "SADD b 2", "SDIFF a b c"}, "*2\r\n$1\r\n1\r\n$1\r\n3\r\n"},
+		{"sdiffstore_empty_deletes", []string{"SET d v", "SADD a 1", "SDIFFSTORE d a a", "EXISTS d"}, ":0\r\n"},
+		{"sinterstore_count", []string{"SADD a 1 2 3", "SADD b 2 3 4", "SINTERSTORE d a b"}, ":2\r\n"},
+		{"sinter_wrong_type", []string{"SADD a 1", "SET b v", "SINTER a b"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"sintercard", []string{"SADD a 1 2 3", "SADD b 2 3 4", "SINTERCARD 2 a b"}, ":2\r\n"},
+		{"sintercard_limit", []string{"SADD a 1 2 3", "SADD b 2 3 4", "SINTERCARD 2 a b LIMIT 1"}, ":1\r\n"},
+		{"sintercard_numkeys_zero", []string{"SINTERCARD 0 a"}, "-ERR numkeys should be greater than 0\r\n"},
+		{"sintercard_too_many_keys", []string{"SINTERCARD 3 a b"}, "-ERR Number of keys can't be greater than number of args\r\n"},
+		{"sintercard_negative_limit", []string{"SINTERCARD 1 a LIMIT -1"}, "-ERR LIMIT can't be negative\r\n"},
+	})
+}
@@ -0,0 +1,563 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"gored/keyspace"
+	"gored/respser"
+)
+
+func init() {
+	registerCommand("GET", getCommand, 2, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("SET", setCommand, -3, flagWrite, 1, 1, 1)
+	registerCommand("SETNX", setnxCommand, 3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("SETEX", setexCommand, 4, flagWrite, 1, 1, 1)
+	registerCommand("PSETEX", psetexCommand, 4, flagWrite, 1, 1, 1)
+	registerCommand("GETSET", getsetCommand, 3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("GETDEL", getdelCommand, 2, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("GETEX", getexCommand, -2, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("INCR", incrCommand, 2, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("DECR", decrCommand, 2, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("INCRBY", incrbyCommand, 3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("DECRBY", decrbyCommand, 3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("INCRBYFLOAT", incrbyfloatCommand, 3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("APPEND", appendCommand, 3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("STRLEN", strlenCommand, 2, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("GETRANGE", getrangeCommand, 4, flagReadonly, 1, 1, 1)
+	registerCommand("SUBSTR", getrangeCommand, 4, flagReadonly, 1, 1, 1)
+	registerCommand("SETRANGE", setrangeCommand, 4, flagWrite, 1, 1, 1)
+	registerCommand("LCS", lcsCommand, -3, flagReadonly, 1, 2, 1)
+	registerCommand("MGET", mgetCommand, -2, flagReadonly|flagFast, 1, -1, 1)
+	registerCommand("MSET", msetCommand, -3, flagWrite, 1, -1, 2)
+	registerCommand("MSETNX", msetnxCommand, -3, flagWrite, 1, -1, 2)
+}
+
+func getCommand(c *client, args []string) respser.RespEncoder {
+	s, ok, err := c.db.GetString(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	return stringReply(s, ok)
+}
+
+// setCommand implements
+// SET key value [NX|XX] [GET] [EX seconds|PX milliseconds|EXAT unix-time-seconds|PXAT unix-time-milliseconds|KEEPTTL].
+func setCommand(c *client, args []string) respser.RespEncoder {
+	var nx, xx, get bool
+	expire, expireArg := "", ""
+	for i := 3; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		switch {
+		case opt == "NX" && !xx:
+			nx = true
+		case opt == "XX" && !nx:
+			xx = true
+		case opt == "GET":
+			get = true
+		case opt == "KEEPTTL" && expire == "":
+			expire = opt
+		case isExpireOption(opt) && expire == "" && i+1 < len(args):
+			expire, expireArg = opt, args[i+1]
+			i++
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+
+	var at int64
+	if expire != "" && expire != "KEEPTTL" {
+		var reply *respser.ErrorString
+		if at, reply = expireTime(c.db, expire, expireArg, "set"); reply != nil {
+			return reply
+		}
+	}
+	return setGeneric(c, args[1], args[2], nx, xx, get, expire, at)
+}
+
+func setnxCommand(c *client, args []string) respser.RespEncoder {
+	if c.db.Exists(args[1]) {
+		return &respser.Integer{N: 0}
+	}
+	c.db.Set(args[1], keyspace.String(args[2]))
+	return &respser.Integer{N: 1}
+}
+
+func setexCommand(c *client, args []string) respser.RespEncoder {
+	at, reply := expireTime(c.db, "EX", args[2], "setex")
+	if reply != nil {
+		return reply
+	}
+	return setGeneric(c, args[1], args[3], false, false, false, "EX", at)
+}
+
+func psetexCommand(c *client, args []string) respser.RespEncoder {
+	at, reply := expireTime(c.db, "PX", args[2], "psetex")
+	if reply != nil {
+		return reply
+	}
+	return setGeneric(c, args[1], args[3], false, false, false, "PX", at)
+}
+
+// setGeneric is the shared part of the SET family. expire is the option
+// that set the expire time at, or KEEPTTL, or empty for none.
+func setGeneric(c *client, key, value string, nx, xx, get bool, expire string, at int64) respser.RespEncoder {
+	var old keyspace.String
+	var found bool
+	if get {
+		var err error
+		if old, found, err = c.db.GetString(key); err != nil {
+			return errorReply(errWrongType)
+		}
+	}
+
+	exists := c.db.Exists(key)
+	if nx && exists || xx && !exists {
+		if get {
+			return stringReply(old, found)
+		}
+		return &respser.BulkString{}
+	}
+
+	if expire == "KEEPTTL" {
+		c.db.Update(key, keyspace.String(value))
+	} else {
+		c.db.Set(key, keyspace.String(value))
+	}
+	if expire != "" && expire != "KEEPTTL" {
+		c.db.SetExpire(key, at)
+	}
+
+	if get {
+		return stringReply(old, found)
+	}
+	return &respser.SimpleString{S: "OK"}
+}
+
+func getsetCommand(c *client, args []string) respser.RespEncoder {
+	old, found, err := c.db.GetString(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	c.db.Set(args[1], keyspace.String(args[2]))
+	return stringReply(old, found)
+}
+
+func getdelCommand(c *client, args []string) respser.RespEncoder {
+	s, ok, err := c.db.GetString(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if ok {
+		c.db.Delete(args[1])
+	}
+	return stringReply(s, ok)
+}
+
+// getexCommand implements
+// GETEX key [EX seconds|PX milliseconds|EXAT unix-time-seconds|PXAT unix-time-milliseconds|PERSIST].
+func getexCommand(c *client, args []string) respser.RespEncoder {
+	expire, expireArg := "", ""
+	for i := 2; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		switch {
+		case opt == "PERSIST" && expire == "":
+			expire = opt
+		case isExpireOption(opt) && expire == "" && i+1 < len(args):
+			expire, expireArg = opt, args[i+1]
+			i++
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+
+	var at int64
+	if expire != "" && expire != "PERSIST" {
+		var reply *respser.ErrorString
+		if at, reply = expireTime(c.db, expire, expireArg, "getex"); reply != nil {
+			return reply
+		}
+	}
+
+	s, ok, err := c.db.GetString(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.BulkString{}
+	}
+	switch expire {
+	case "":
+	case "PERSIST":
+		c.db.Persist(args[1])
+	default:
+		c.db.SetExpire(args[1], at)
+	}
+	return stringReply(s, true)
+}
+
+// mgetCommand replies with the value of every key, and with nil for keys
+// that are missing or do not hold a string.
+func mgetCommand(c *client, args []string) respser.RespEncoder {
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	for _, key := range args[1:] {
+		s, ok, _ := c.db.GetString(key)
+		reply.AddElement(stringReply(s, ok))
+	}
+	return reply
+}
+
+func msetCommand(c *client, args []string) respser.RespEncoder {
+	if len(args)%2 == 0 {
+		return wrongArityReply(args[0])
+	}
+	for i := 1; i < len(args); i += 2 {
+		c.db.Set(args[i], keyspace.String(args[i+1]))
+	}
+	return &respser.SimpleString{S: "OK"}
+}
+
+// msetnxCommand sets all of the keys only if none of them exists.
+func msetnxCommand(c *client, args []string) respser.RespEncoder {
+	if len(args)%2 == 0 {
+		return wrongArityReply(args[0])
+	}
+	for i := 1; i < len(args); i += 2 {
+		if c.db.Exists(args[i]) {
+			return &respser.Integer{N: 0}
+		}
+	}
+	for i := 1; i < len(args); i += 2 {
+		c.db.Set(args[i], keyspace.String(args[i+1]))
+	}
+	return &respser.Integer{N: 1}
+}
+
+func incrCommand(c *client, args []string) respser.RespEncoder {
+	return incrDecr(c, args[1], 1)
+}
+
+func decrCommand(c *client, args []string) respser.RespEncoder {
+	return incrDecr(c, args[1], -1)
+}
+
+func incrbyCommand(c *client, args []string) respser.RespEncoder {
+	incr, ok := parseInt64(args[2])
+	if !ok {
+		return errorReply(errNotInteger)
+	}
+	return incrDecr(c, args[1], incr)
+}
+
+func decrbyCommand(c *client, args []string) respser.RespEncoder {
+	decr, ok := parseInt64(args[2])
+	if !ok {
+		return errorReply(errNotInteger)
+	}
+	if decr == math.MinInt64 {
+		return errorReply("ERR decrement would overflow")
+	}
+	return incrDecr(c, args[1], -decr)
+}
+
+// incrDecr adds incr to the integer stored at key, which counts as 0 if
+// the key does not exist. The expire time of the key is kept.
+func incrDecr(c *client, key string, incr int64) respser.RespEncoder {
+	s, ok, err := c.db.GetString(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	var n int64
+	if ok {
+		if n, ok = parseInt64(string(s)); !ok {
+			return errorReply(errNotInteger)
+		}
+	}
+	if incr > 0 && n > math.MaxInt64-incr || incr < 0 && n < math.MinInt64-incr {
+		return errorReply("ERR increment or decrement would overflow")
+	}
+	n += incr
+	c.db.Update(key, keyspace.String(strconv.FormatInt(n, 10)))
+	return &respser.Integer{N: n}
+}
+
+func incrbyfloatCommand(c *client, args []string) respser.RespEncoder {
+	key := args[1]
+	s, ok, err := c.db.GetString(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	var f float64
+	if ok {
+		if f, ok = parseFloat(string(s)); !ok {
+			return errorReply("ERR value is not a valid float")
+		}
+	}
+	incr, ok := parseFloat(args[2])
+	if !ok {
+		return errorReply("ERR value is not a valid float")
+	}
+	f += incr
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return errorReply("ERR increment would produce NaN or Infinity")
+	}
+	v := strconv.FormatFloat(f, 'f', -1, 64)
+	c.db.Update(key, keyspace.String(v))
+	return respser.NewBulkString(v)
+}
+
+func appendCommand(c *client, args []string) respser.RespEncoder {
+	s, _, err := c.db.GetString(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if reply := checkStringLength(c, int64(len(s)), int64(len(args[2]))); reply != nil {
+		return reply
+	}
+	s = append(s, args[2]...)
+	c.db.Update(args[1], s)
+	return &respser.Integer{N: int64(len(s))}
+}
+
+func strlenCommand(c *client, args []string) respser.RespEncoder {
+	s, _, err := c.db.GetString(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	return &respser.Integer{N: int64(len(s))}
+}
+
+// getrangeCommand implements GETRANGE key start end, where negative
+// offsets count from the end of the string.
+func getrangeCommand(c *client, args []string) respser.RespEncoder {
+	start, ok1 := parseInt64(args[2])
+	end, ok2 := parseInt64(args[3])
+	if !ok1 || !ok2 {
+		return errorReply(errNotInteger)
+	}
+	s, _, err := c.db.GetString(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+
+	n := int64(len(s))
+	if start < 0 && end < 0 && start > end {
+		return respser.NewBulkString("")
+	}
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if end < 0 {
+		end = max(n+end, 0)
+	}
+	end = min(end, n-1)
+	if n == 0 || start > end {
+		return respser.NewBulkString("")
+	}
+	return respser.NewBulkBytes(s[start : end+1])
+}
+
+// setrangeCommand implements SETRANGE key offset value. The string is
+// padded with zero bytes if it is shorter than offset.
+func setrangeCommand(c *client, args []string) respser.RespEncoder {
+	offset, ok := parseInt64(args[2])
+	if !ok {
+		return errorReply(errNotInteger)
+	}
+	if offset < 0 {
+		return errorReply("ERR offset is out of range")
+	}
+	value := args[3]
+	s, found, err := c.db.GetString(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	// An empty value changes nothing, not even a missing key.
+	if value == "" {
+		return &respser.Integer{N: int64(len(s))}
+	}
+	if reply := checkStringLength(c, offset, int64(len(value))); reply != nil {
+		return reply
+	}
+
+	if end := int(offset) + len(value); end > len(s) {
+		s = append(s, make([]byte, end-len(s))...)
+	}
+	copy(s[offset:], value)
+	if found {
+		c.db.Update(args[1], s)
+	} else {
+		c.db.Set(args[1], s)
+	}
+	return &respser.Integer{N: int64(len(s))}
+}
+
+// lcsCommand implements LCS key1 key2 [LEN] [IDX] [MINMATCHLEN len] [WITHMATCHLEN].
+func lcsCommand(c *client, args []string) respser.RespEncoder {
+	var getLen, getIdx, withMatchLen bool
+	var minMatchLen int64
+	for i := 3; i < len(args); i++ {
+		switch opt := strings.ToUpper(args[i]); {
+		case opt == "LEN":
+			getLen = true
+		case opt == "IDX":
+			getIdx = true
+		case opt == "WITHMATCHLEN":
+			withMatchLen = true
+		case opt == "MINMATCHLEN" && i+1 < len(args):
+			n, ok := parseInt64(args[i+1])
+			if !ok {
+				return errorReply(errNotInteger)
+			}
+			minMatchLen = max(n, 0)
+			i++
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+	if getLen && getIdx {
+		return errorReply("ERR If you want both the length and indexes, please just use IDX.")
+	}
+
+	a, _, err1 := c.db.GetString(args[1])
+	b, _, err2 := c.db.GetString(args[2])
+	if err1 != nil || err2 != nil {
+		return errorReply("ERR The specified keys must contain string values")
+	}
+
+	// The table holds the length of the LCS of every pair of prefixes of a
+	// and b, so its size is bounded like any other allocation a client can
+	// cause.
+	cells := (int64(len(a)) + 1) * (int64(len(b)) + 1)
+	if cells > math.MaxUint32-1 || checkStringLength(c, 0, 4*cells) != nil {
+		return errorReply("ERR Insufficient memory, transient memory for LCS exceeds proto-max-bulk-len")
+	}
+	width := len(b) + 1
+	table := make([]uint32, cells)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				table[i*width+j] = table[(i-1)*width+j-1] + 1
+			} else {
+				table[i*width+j] = max(table[(i-1)*width+j], table[i*width+j-1])
+			}
+		}
+	}
+	lcsLen := table[len(a)*width+len(b)]
+	if getLen {
+		return &respser.Integer{N: int64(lcsLen)}
+	}
+
+	// Walk the table back from the end, collecting the LCS and, for IDX,
+	// the ranges of consecutive matching bytes.
+	result := make([]byte, lcsLen)
+	matches := []respser.RespEncoder{}
+	idx := lcsLen
+	aStart, aEnd, bStart, bEnd := len(a), 0, 0, 0
+	for i, j := len(a), len(b); i > 0 && j > 0; {
+		emit := false
+		if a[i-1] == b[j-1] {
+			result[idx-1] = a[i-1]
+			if aStart == len(a) {
+				aStart, aEnd, bStart, bEnd = i-1, i-1, j-1, j-1
+			} else {
+				aStart, bStart = aStart-1, bStart-1
+			}
+			emit = aStart == 0 || bStart == 0
+			idx, i, j = idx-1, i-1, j-1
+		} else {
+			if table[(i-1)*width+j] > table[i*width+j-1] {
+				i--
+			} else {
+				j--
+			}
+			emit = aStart != len(a)
+		}
+
+		if emit {
+			if matchLen := int64(aEnd - aStart + 1); minMatchLen == 0 || matchLen >= minMatchLen {
+				match := &respser.Array{}
+				match.AddElement(intPair(aStart, aEnd))
+				match.AddElement(intPair(bStart, bEnd))
+				if withMatchLen {
+					match.AddElement(&respser.Integer{N: matchLen})
+				}
+				matches = append(matches, match)
+			}
+			aStart = len(a)
+		}
+	}
+
+	if !getIdx {
+		return respser.NewBulkBytes(result)
+	}
+	reply := &respser.Map{}
+	reply.AddEntry(respser.NewBulkString("matches"), &respser.Array{Elements: &matches})
+	reply.AddEntry(respser.NewBulkString("len"), &respser.Integer{N: int64(lcsLen)})
+	return reply
+}
+
+func intPair(a, b int) *respser.Array {
+	return &respser.Array{Elements: &[]respser.RespEncoder{
+		&respser.Integer{N: int64(a)},
+		&respser.Integer{N: int64(b)},
+	}}
+}
+
+// checkStringLength replies with an error if a string of size bytes, grown
+// by add more, would be larger than the configured limit on bulk strings.
+// As in Redis, a length that overflows an int64 is too large whatever the
+// limit.
+func checkStringLength(c *client, size, add int64) *respser.ErrorString {
+	n := size + add
+	if n < size {
+		return errorReply("ERR string exceeds maximum allowed size (proto-max-bulk-len)")
+	}
+	if limit := c.cfg.limits.MaxBulkLen; limit > 0 && n > int64(limit) {
+		return errorReply("ERR string exceeds maximum allowed size (proto-max-bulk-len)")
+	}
+	return nil
+}
+
+func isExpireOption(opt string) bool {
+	return opt == "EX" || opt == "PX" || opt == "EXAT" || opt == "PXAT"
+}
+
+// expireTime converts the argument of an EX, PX, EXAT or PXAT option to an
+// absolute unix time in milliseconds. cmd names the command in errors.
+func expireTime(db *keyspace.DB, opt, arg, cmd string) (int64, *respser.ErrorString) {
+	n, ok := parseInt64(arg)
+	if !ok {
+		return 0, errorReply(errNotInteger)
+	}
+	if n <= 0 {
+		return 0, invalidExpireReply(cmd)
+	}
+	if opt == "EX" || opt == "EXAT" {
+		if n > math.MaxInt64/1000 {
+			return 0, invalidExpireReply(cmd)
+		}
+		n *= 1000
+	}
+	if opt == "EX" || opt == "PX" {
+		now := db.Now()
+		if n > math.MaxInt64-now {
+			return 0, invalidExpireReply(cmd)
+		}
+		n += now
+	}
+	return n, nil
+}
+
+func invalidExpireReply(cmd string) *respser.ErrorString {
+	return errorReply("ERR invalid expire time in '%s' command", cmd)
+}
+
+// stringReply replies with s, or with the null bulk string if ok is false.
+func stringReply(s keyspace.String, ok bool) respser.RespEncoder {
+	if !ok {
+		return &respser.BulkString{}
+	}
+	return respser.NewBulkBytes(s)
+}
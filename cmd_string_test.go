@@ -0,0 +1,144 @@
+package main
+
+import "testing"
+
+func TestGetex(t *testing.T) {
+	runCases(t, []commandCase{
+		{"persist_then_ex", []string{"SET k v", "GETEX k PERSIST EX 10"}, "-ERR syntax error\r\n"},
+		{"ex_then_persist", []string{"SET k v", "GETEX k EX 10 PERSIST"}, "-ERR syntax error\r\n"},
+		{"ex_then_px", []string{"SET k v", "GETEX k EX 10 PX 10"}, "-ERR syntax error\r\n"},
+		{"ex_without_time", []string{"SET k v", "GETEX k EX"}, "-ERR syntax error\r\n"},
+		{"ex_zero", []string{"SET k v", "GETEX k EX 0"}, "-ERR invalid expire time in 'getex' command\r\n"},
+		{"px_negative", []string{"SET k v", "GETEX k PX -1"}, "-ERR invalid expire time in 'getex' command\r\n"},
+		{"ex_overflow", []string{"SET k v", "GETEX k EX 9223372036854775807"}, "-ERR invalid expire time in 'getex' command\r\n"},
+		{"ex_not_integer", []string{"SET k v", "GETEX k EX ten"}, "-ERR value is not an integer or out of range\r\n"},
+		{"invalid_on_missing_key", []string{"GETEX k EX 0"}, "-ERR invalid expire time in 'getex' command\r\n"},
+		{"ex_sets_ttl", []string{"SET k v", "GETEX k EX 10", "TTL k"}, ":10\r\n"},
+		{"persist_removes_ttl", []string{"SET k v EX 10", "GETEX k PERSIST", "TTL k"}, ":-1\r\n"},
+		{"no_option_keeps_ttl", []string{"SET k v EX 10", "GETEX k", "TTL k"}, ":10\r\n"},
+		{"replies_value", []string{"SET k v", "GETEX k PX 100"}, "$1\r\nv\r\n"},
+		{"missing_key", []string{"GETEX k EX 10"}, "$-1\r\n"},
+		{"wrong_type", []string{"RPUSH k a", "GETEX k EX 10"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+	})
+}
+
+func TestSetGet(t *testing.T) {
+	runCases(t, []commandCase{
+		{"get_missing", []string{"GET k"}, "$-1\r\n"},
+		{"set_get", []string{"SET k v", "GET k"}, "$1\r\nv\r\n"},
+		{"set_nx_existing", []string{"SET k v", "SET k w NX"}, "$-1\r\n"},
+		{"set_xx_missing", []string{"SET k v XX"}, "$-1\r\n"},
+		{"set_get_option", []string{"SET k v", "SET k w GET"}, "$1\r\nv\r\n"},
+		{"set_nx_xx", []string{"SET k v NX XX"}, "-ERR syntax error\r\n"},
+		{"set_keepttl", []string{"SET k v EX 100", "SET k w KEEPTTL", "TTL k"}, ":100\r\n"},
+		{"set_clears_ttl", []string{"SET k v EX 100", "SET k w", "TTL k"}, ":-1\r\n"},
+		{"set_ex_zero", []string{"SET k v EX 0"}, "-ERR invalid expire time in 'set' command\r\n"},
+		{"get_wrong_type", []string{"RPUSH k a", "GET k"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+	})
+}
+
+func TestIncr(t *testing.T) {
+	runCases(t, []commandCase{
+		{"incr_missing", []string{"INCR k"}, ":1\r\n"},
+		{"incrby", []string{"SET k 10", "INCRBY k -15"}, ":-5\r\n"},
+		{"decrby", []string{"SET k 10", "DECRBY k 15"}, ":-5\r\n"},
+		{"incr_not_integer", []string{"SET k 1.5", "INCR k"}, "-ERR value is not an integer or out of range\r\n"},
+		{"incr_leading_space", []string{"SET k \" 1\"", "INCR k"}, "-ERR value is not an integer or out of range\r\n"},
+		{"incr_overflow", []string{"SET k 9223372036854775807", "INCR k"}, "-ERR increment or decrement would overflow\r\n"},
+		{"decr_overflow", []string{"SET k -9223372036854775808", "DECR k"}, "-ERR increment or decrement would overflow\r\n"},
+		{"incrbyfloat", []string{"SET k 10.5", "INCRBYFLOAT k 0.1"}, "$4\r\n10.6\r\n"},
+		{"incrbyfloat_exponent", []string{"SET k 5.0e3", "INCRBYFLOAT k 2.0e2"}, "$4\r\n5200\r\n"},
+		{"incrbyfloat_inf", []string{"INCRBYFLOAT k inf"}, "-ERR increment would produce NaN or Infinity\r\n"},
+		{"incrbyfloat_plus_inf", []string{"INCRBYFLOAT k +inf"}, "-ERR increment would produce NaN or Infinity\r\n"},
+		{"incrbyfloat_overflow", []string{"INCRBYFLOAT k 1e400"}, "-ERR value is not a valid float\r\n"},
+		{"incrbyfloat_underflow", []string{"INCRBYFLOAT k 1e-400"}, "-ERR value is not a valid float\r\n"},
+		{"incrbyfloat_underflow_not_stored", []string{"INCRBYFLOAT k 1e-400", "GET k"}, "$-1\r\n"},
+		{"incrbyfloat_zero", []string{"INCRBYFLOAT k 0e-400"}, "$1\r\n0\r\n"},
+		{"incrbyfloat_overflowing_value", []string{"SET k 1e400", "INCRBYFLOAT k 1"}, "-ERR value is not a valid float\r\n"},
+		{"incr_keeps_ttl", []string{"SET k 1 EX 100", "INCR k", "TTL k"}, ":100\r\n"},
+	})
+}
+
+func TestAppendStrlen(t *testing.T) {
+	runCases(t, []commandCase{
+		{"append_missing", []string{"APPEND k abc"}, ":3\r\n"},
+		{"append_existing", []string{"SET k abc", "APPEND k de", "GET k"}, "$5\r\nabcde\r\n"},
+		{"strlen", []string{"SET k abc", "STRLEN k"}, ":3\r\n"},
+		{"strlen_missing", []string{"STRLEN k"}, ":0\r\n"},
+	})
+}
+
+func TestGetrange(t *testing.T) {
+	set := "SET k \"This is a string\""
+	runCases(t, []commandCase{
+		{"prefix", []string{set, "GETRANGE k 0 3"}, "$4\r\nThis\r\n"},
+		{"negative", []string{set, "GETRANGE k -3 -1"}, "$3\r\ning\r\n"},
+		{"whole", []string{set, "GETRANGE k 0 -1"}, "$16\r\nThis is a string\r\n"},
+		{"end_past_length", []string{set, "GETRANGE k 10 100"}, "$6\r\nstring\r\n"},
+		{"start_before_zero", []string{set, "GETRANGE k -100 3"}, "$4\r\nThis\r\n"},
+		{"start_after_end", []string{set, "GETRANGE k 5 3"}, "$0\r\n\r\n"},
+		{"negative_start_after_end", []string{set, "GETRANGE k -1 -5"}, "$0\r\n\r\n"},
+		{"start_past_length", []string{set, "GETRANGE k 100 200"}, "$0\r\n\r\n"},
+		{"missing_key", []string{"GETRANGE k 0 -1"}, "$0\r\n\r\n"},
+		{"substr", []string{set, "SUBSTR k -6 -1"}, "$6\r\nstring\r\n"},
+		{"not_integer", []string{set, "GETRANGE k a 1"}, "-ERR value is not an integer or out of range\r\n"},
+	})
+}
+
+func TestSetrange(t *testing.T) {
+	runCases(t, []commandCase{
+		{"overwrite", []string{"SET k \"Hello World\"", "SETRANGE k 6 Redis", "GET k"}, "$11\r\nHello Redis\r\n"},
+		{"zero_padding", []string{"SETRANGE k 5 hi", "GET k"}, "$7\r\n\x00\x00\x00\x00\x00hi\r\n"},
+		{"padding_existing", []string{"SET k ab", "SETRANGE k 4 c", "GET k"}, "$5\r\nab\x00\x00c\r\n"},
+		{"reply_is_length", []string{"SETRANGE k 5 hi"}, ":7\r\n"},
+		{"empty_value_missing_key", []string{"SETRANGE k 5 \"\"", "GET k"}, "$-1\r\n"},
+		{"empty_value_existing_key", []string{"SET k ab", "SETRANGE k 5 \"\""}, ":2\r\n"},
+		{"negative_offset", []string{"SETRANGE k -1 x"}, "-ERR offset is out of range\r\n"},
+		{"too_long", []string{"SETRANGE k 536870912 x"}, "-ERR string exceeds maximum allowed size (proto-max-bulk-len)\r\n"},
+		{"offset_overflow", []string{"SETRANGE s 9223372036854775807 x"}, "-ERR string exceeds maximum allowed size (proto-max-bulk-len)\r\n"},
+		{"keeps_ttl", []string{"SET k ab EX 100", "SETRANGE k 1 c", "TTL k"}, ":100\r\n"},
+	})
+}
+
+func TestLcs(t *testing.T) {
+	set := []string{"SET key1 ohmytext", "SET key2 mynewtext"}
+	with := func(cmd string) []string { return append(append([]string{}, set...), cmd) }
+	runCases(t, []commandCase{
+		{"string", with("LCS key1 key2"), "$6\r\nmytext\r\n"},
+		{"len", with("LCS key1 key2 LEN"), ":6\r\n"},
+		{"idx", with("LCS key1 key2 IDX"),
+			"*4\r\n$7\r\nmatches\r\n*2\r\n" +
+				"*2\r\n*2\r\n:4\r\n:7\r\n*2\r\n:5\r\n:8\r\n" +
+				"*2\r\n*2\r\n:2\r\n:3\r\n*2\r\n:0\r\n:1\r\n" +
+				"$3\r\nlen\r\n:6\r\n"},
+		{"idx_minmatchlen_withmatchlen", with("LCS key1 key2 IDX MINMATCHLEN 4 WITHMATCHLEN"),
+			"*4\r\n$7\r\nmatches\r\n*1\r\n" +
+				"*3\r\n*2\r\n:4\r\n:7\r\n*2\r\n:5\r\n:8\r\n:4\r\n" +
+				"$3\r\nlen\r\n:6\r\n"},
+		{"idx_withmatchlen", with("LCS key1 key2 IDX WITHMATCHLEN"),
+			"*4\r\n$7\r\nmatches\r\n*2\r\n" +
+				"*3\r\n*2\r\n:4\r\n:7\r\n*2\r\n:5\r\n:8\r\n:4\r\n" +
+				"*3\r\n*2\r\n:2\r\n:3\r\n*2\r\n:0\r\n:1\r\n:2\r\n" +
+				"$3\r\nlen\r\n:6\r\n"},
+		{"match_at_start", []string{"SET a abcx", "SET b abcy", "LCS a b IDX"},
+			"*4\r\n$7\r\nmatches\r\n*1\r\n*2\r\n*2\r\n:0\r\n:2\r\n*2\r\n:0\r\n:2\r\n$3\r\nlen\r\n:3\r\n"},
+		{"nothing_in_common", []string{"SET a abc", "SET b xyz", "LCS a b"}, "$0\r\n\r\n"},
+		{"missing_keys", []string{"LCS a b"}, "$0\r\n\r\n"},
+		{"len_and_idx", with("LCS key1 key2 LEN IDX"), "-ERR If you want both the length and indexes, please just use IDX.\r\n"},
+		{"unknown_option", with("LCS key1 key2 FOO"), "-ERR syntax error\r\n"},
+		{"wrong_type", []string{"RPUSH a x", "LCS a b"}, "-ERR The specified keys must contain string values\r\n"},
+	})
+}
+
+func TestMgetMset(t *testing.T) {
+	runCases(t, []commandCase{
+		{"mset_mget", []string{"MSET a 1 b 2", "MGET a b c"}, "*3\r\n$1\r\n1\r\n$1\r\n2\r\n$-1\r\n"},
+		{"mget_wrong_type", []string{"RPUSH a x", "SET b 2", "MGET a b"}, "*2\r\n$-1\r\n$1\r\n2\r\n"},
+		{"mset_odd_args", []string{"MSET a 1 b"}, "-ERR wrong number of arguments for 'mset' command\r\n"},
+		{"mset_clears_ttl", []string{"SET a 1 EX 100", "MSET a 2", "TTL a"}, ":-1\r\n"},
+		{"mset_repeated_key", []string{"MSET a 1 a 2", "GET a"}, "$1\r\n2\r\n"},
+		{"msetnx", []string{"MSETNX a 1 b 2", "MGET a b"}, "*2\r\n$1\r\n1\r\n$1\r\n2\r\n"},
+		{"msetnx_existing", []string{"SET b 0", "MSETNX a 1 b 2"}, ":0\r\n"},
+		{"msetnx_sets_none", []string{"SET b 0", "MSETNX a 1 b 2", "GET a"}, "$-1\r\n"},
+	})
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"math"
+	"strings"
+
+	"gored/keyspace"
+	"gored/respser"
+)
+
+func init() {
+	registerCommand("ZADD", zaddCommand, -4, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("ZINCRBY", zincrbyCommand, 4, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("ZREM", zremCommand, -3, flagWrite|flagFast, 1, 1, 1)
+	registerCommand("ZSCORE", zscoreCommand, 3, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("ZMSCORE", zmscoreCommand, -3, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("ZCARD", zcardCommand, 2, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("ZCOUNT", zcountCommand, 4, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("ZRANK", zrankCommand, -3, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("ZREVRANK", zrevrankCommand, -3, flagReadonly|flagFast, 1, 1, 1)
+	registerCommand("ZRANGE", zrangeCommand, -4, flagReadonly, 1, 1, 1)
+	registerCommand("ZREVRANGE", zrevrangeCommand, -4, flagReadonly, 1, 1, 1)
+	registerCommand("ZRANGEBYSCORE", zrangebyscoreCommand, -4, flagReadonly, 1, 1, 1)
+	registerCommand("ZREVRANGEBYSCORE", zrevrangebyscoreCommand, -4, flagReadonly, 1, 1, 1)
+	registerCommand("ZRANGEBYLEX", zrangebylexCommand, -4, flagReadonly, 1, 1, 1)
+	registerCommand("ZREVRANGEBYLEX", zrevrangebylexCommand, -4, flagReadonly, 1, 1, 1)
+}
+
+// zaddCommand implements
+// ZADD key [NX|XX] [GT|LT] [CH] [INCR] score member [score member ...].
+// GT and LT only keep existing members from moving down or up. The reply
+// counts the new members, and the moved ones too with CH; with INCR, which
+// takes a single pair and adds to the score, it is the new score, or null
+// if the options kept the member from changing.
+func zaddCommand(c *client, args []string) respser.RespEncoder {
+	var nx, xx, gt, lt, ch, incr bool
+	i := 2
+options:
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "GT":
+			gt = true
+		case "LT":
+			lt = true
+		case "CH":
+			ch = true
+		case "INCR":
+			incr = true
+		default:
+			break options
+		}
+	}
+	pairs := args[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return errorReply(errSyntax)
+	}
+	if nx && xx {
+		return errorReply("ERR XX and NX options at the same time are not compatible")
+	}
+	if gt && lt || (gt || lt) && nx {
+		return errorReply("ERR GT, LT, and/or NX options at the same time are not compatible")
+	}
+	if incr && len(pairs) > 2 {
+		return errorReply("ERR INCR option supports a single increment-element pair")
+	}
+	scores := make([]float64, len(pairs)/2)
+	for j := range scores {
+		var ok bool
+		if scores[j], ok = parseFloat(pairs[2*j]); !ok {
+			return errorReply("ERR value is not a valid float")
+		}
+	}
+
+	key := args[1]
+	z, ok, err := c.db.GetZSet(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		if xx {
+			if incr {
+				return &respser.BulkString{}
+			}
+			return &respser.Integer{N: 0}
+		}
+		z = keyspace.NewZSet()
+		c.db.Set(key, z)
+	}
+	var added, changed int64
+	for j, score := range scores {
+		m := pairs[2*j+1]
+		current, exists := z.Score(m)
+		skip := exists && nx || !exists && xx
+		if !skip && exists {
+			if incr {
+				score += current
+				if math.IsNaN(score) {
+					return errorReply("ERR resulting score is not a number (NaN)")
+				}
+			}
+			skip = gt && score <= current || lt && score >= current
+		}
+		if skip {
+			if incr {
+				return &respser.BulkString{}
+			}
+			continue
+		}
+		if !exists {
+			added++
+		} else if score != current {
+			changed++
+		}
+		z.Add(m, score)
+		if incr {
+			return &respser.Double{F: score}
+		}
+	}
+	if ch {
+		return &respser.Integer{N: added + changed}
+	}
+	return &respser.Integer{N: added}
+}
+
+func zincrbyCommand(c *client, args []string) respser.RespEncoder {
+	incr, ok := parseFloat(args[2])
+	if !ok {
+		return errorReply("ERR value is not a valid float")
+	}
+	key, m := args[1], args[3]
+	z, ok, err := c.db.GetZSet(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		z = keyspace.NewZSet()
+		c.db.Set(key, z)
+	}
+	score, _ := z.Score(m)
+	score += incr
+	if math.IsNaN(score) {
+		return errorReply("ERR resulting score is not a number (NaN)")
+	}
+	z.Add(m, score)
+	return &respser.Double{F: score}
+}
+
+func zremCommand(c *client, args []string) respser.RespEncoder {
+	key := args[1]
+	z, ok, err := c.db.GetZSet(key)
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	var removed int64
+	for _, m := range args[2:] {
+		if z.Remove(m) {
+			removed++
+		}
+	}
+	if z.Len() == 0 {
+		c.db.Delete(key)
+	}
+	return &respser.Integer{N: removed}
+}
+
+func zscoreCommand(c *client, args []string) respser.RespEncoder {
+	z, ok, err := c.db.GetZSet(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.BulkString{}
+	}
+	return scoreReply(z, args[2])
+}
+
+func zmscoreCommand(c *client, args []string) respser.RespEncoder {
+	z, ok, err := c.db.GetZSet(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	for _, m := range args[2:] {
+		if !ok {
+			reply.AddElement(&respser.BulkString{})
+			continue
+		}
+		reply.AddElement(scoreReply(z, m))
+	}
+	return reply
+}
+
+func zcardCommand(c *client, args []string) respser.RespEncoder {
+	z, ok, err := c.db.GetZSet(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	return &respser.Integer{N: int64(z.Len())}
+}
+
+func zcountCommand(c *client, args []string) respser.RespEncoder {
+	r, errReply := parseScoreRange(args[2], args[3])
+	if errReply != nil {
+		return errReply
+	}
+	z, ok, err := c.db.GetZSet(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	if !ok {
+		return &respser.Integer{N: 0}
+	}
+	return &respser.Integer{N: int64(z.CountScores(r))}
+}
+
+func zrankCommand(c *client, args []string) respser.RespEncoder {
+	return zrankGeneric(c, args, false)
+}
+
+func zrevrankCommand(c *client, args []string) respser.RespEncoder {
+	return zrankGeneric(c, args, true)
+}
+
+// zrankGeneric implements ZRANK and ZREVRANK key member [WITHSCORE], which
+// reply with the rank of member, and its score too with WITHSCORE.
+func zrankGeneric(c *client, args []string, reverse bool) respser.RespEncoder {
+	if len(args) > 4 || len(args) == 4 && !strings.EqualFold(args[3], "WITHSCORE") {
+		return errorReply(errSyntax)
+	}
+	withScore := len(args) == 4
+	z, ok, err := c.db.GetZSet(args[1])
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+	var rank int
+	if ok {
+		rank, ok = z.Rank(args[2], reverse)
+	}
+	switch {
+	case !ok && withScore:
+		return &respser.Array{}
+	case !ok:
+		return &respser.BulkString{}
+	case withScore:
+		score, _ := z.Score(args[2])
+		return &respser.Array{Elements: &[]respser.RespEncoder{&respser.Integer{N: int64(rank)}, &respser.Double{F: score}}}
+	}
+	return &respser.Integer{N: int64(rank)}
+}
+
+func zrangeCommand(c *client, args []string) respser.RespEncoder {
+	return zrangeGeneric(c, args, "", false, true)
+}
+
+func zrevrangeCommand(c *client, args []string) respser.RespEncoder {
+	return zrangeGeneric(c, args, "", true, false)
+}
+
+func zrangebyscoreCommand(c *client, args []string) respser.RespEncoder {
+	return zrangeGeneric(c, args, "BYSCORE", false, false)
+}
+
+func zrevrangebyscoreCommand(c *client, args []string) respser.RespEncoder {
+	return zrangeGeneric(c, args, "BYSCORE", true, false)
+}
+
+func zrangebylexCommand(c *client, args []string) respser.RespEncoder {
+	return zrangeGeneric(c, args, "BYLEX", false, false)
+}
+
+func zrevrangebylexCommand(c *client, args []string) respser.RespEncoder {
+	return zrangeGeneric(c, args, "BYLEX", true, false)
+}
+
+// zrangeGeneric implements
+// ZRANGE key start stop [BYSCORE|BYLEX] [REV] [LIMIT offset count] [WITHSCORES]
+// and the older commands it unifies, which have by and reverse fixed
+// instead of taking them as options. The range is one of ranks, scores or
+// members, and with REV its ends come in reverse order too, so BYSCORE
+// takes max before min. LIMIT skips offset members of the range and
+// stops after count of them, unless count is negative.
+func zrangeGeneric(c *client, args []string, by string, reverse, unified bool) respser.RespEncoder {
+	var withScores, limit bool
+	offset, count := int64(0), int64(-1)
+	for i := 4; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		switch {
+		case opt == "WITHSCORES":
+			withScores = true
+		case opt == "LIMIT" && i+2 < len(args):
+			var ok1, ok2 bool
+			offset, ok1 = parseInt64(args[i+1])
+			count, ok2 = parseInt64(args[i+2])
+			if !ok1 || !ok2 {
+				return errorReply(errNotInteger)
+			}
+			limit = true
+			i += 2
+		case unified && (opt == "BYSCORE" || opt == "BYLEX"):
+			by = opt
+		case unified && opt == "REV":
+			reverse = true
+		default:
+			return errorReply(errSyntax)
+		}
+	}
+	if limit && by == "" {
+		return errorReply("ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX")
+	}
+	if withScores && by == "BYLEX" {
+		return errorReply("ERR syntax error, WITHSCORES not supported in combination with BYLEX")
+	}
+
+	lo, hi := args[2], args[3]
+	if reverse && by != "" {
+		lo, hi = hi, lo
+	}
+	// Each kind of range gives the rank the reply starts at, counted in
+	// the direction of the range, and what decides where it ends.
+	var start int
+	var found bool
+	var inRange func(m string, score float64) bool
+	z, exists, err := c.db.GetZSet(args[1])
+	switch by {
+	case "":
+		first, ok1 := parseInt64(lo)
+		last, ok2 := parseInt64(hi)
+		if !ok1 || !ok2 {
+			return errorReply(errNotInteger)
+		}
+		if exists {
+			n := int64(z.Len())
+			if first < 0 {
+				first = max(first+n, 0)
+			}
+			if last < 0 {
+				last += n
+			}
+			last = min(last, n-1)
+			found = first <= last && first < n
+			start, count = int(first), last-first+1
+		}
+		inRange = func(string, float64) bool { return true }
+	case "BYSCORE":
+		r, errReply := parseScoreRange(lo, hi)
+		if errReply != nil {
+			return errReply
+		}
+		if exists {
+			start, found = z.ScoreRank(r, reverse)
+		}
+		inRange = func(_ string, score float64) bool { return r.Contains(score) }
+	case "BYLEX":
+		r, empty, errReply := parseLexRange(lo, hi)
+		if errReply != nil {
+			return errReply
+		}
+		if exists && !empty {
+			start, found = z.LexRank(r, reverse)
+		}
+		inRange = func(m string, _ float64) bool { return r.Contains(m) }
+	}
+	if err != nil {
+		return errorReply(errWrongType)
+	}
+
+	reply := &respser.Array{Elements: &[]respser.RespEncoder{}}
+	if !found || offset < 0 || offset >= int64(z.Len()-start) {
+		return reply
+	}
+	z.Range(start+int(offset), reverse, func(m string, score float64) bool {
+		if count == 0 || !inRange(m, score) {
+			return false
+		}
+		switch {
+		case !withScores:
+			reply.AddElement(respser.NewBulkString(m))
+		case c.protocol >= 3:
+			// RESP3 clients get each member with its score as a pair.
+			reply.AddElement(&respser.Array{Elements: &[]respser.RespEncoder{respser.NewBulkString(m), &respser.Double{F: score}}})
+		default:
+			reply.AddElement(respser.NewBulkString(m))
+			reply.AddElement(&respser.Double{F: score})
+		}
+		count--
+		return true
+	})
+	return reply
+}
+
+// parseScoreRange parses the ends of a range of scores, each a float that
+// is excluded if it follows a '('.
+func parseScoreRange(min, max string) (keyspace.ScoreRange, *respser.ErrorString) {
+	var r keyspace.ScoreRange
+	var ok1, ok2 bool
+	r.Min, r.MinEx, ok1 = parseScoreBound(min)
+	r.Max, r.MaxEx, ok2 = parseScoreBound(max)
+	if !ok1 || !ok2 {
+		return r, errorReply("ERR min or max is not a float")
+	}
+	return r, nil
+}
+
+func parseScoreBound(s string) (score float64, exclusive, ok bool) {
+	if strings.HasPrefix(s, "(") {
+		s, exclusive = s[1:], true
+	}
+	score, ok = parseFloat(s)
+	return score, exclusive, ok
+}
+
+// parseLexRange parses the ends of a range of members: '-' and '+' for
+// no end, or a member after '[' to include it or '(' to exclude it. It
+// reports the range as empty when it starts at '+' or ends at '-'.
+func parseLexRange(min, max string) (r keyspace.LexRange, empty bool, errReply *respser.ErrorString) {
+	invalid := errorReply("ERR min or max not valid string range item")
+	switch {
+	case min == "-":
+		r.NoMin = true
+	case min == "+":
+		empty = true
+	case strings.HasPrefix(min, "[") || strings.HasPrefix(min, "("):
+		r.Min, r.MinEx = min[1:], min[0] == '('
+	default:
+		return r, false, invalid
+	}
+	switch {
+	case max == "+":
+		r.NoMax = true
+	case max == "-":
+		empty = true
+	case strings.HasPrefix(max, "[") || strings.HasPrefix(max, "("):
+		r.Max, r.MaxEx = max[1:], max[0] == '('
+	default:
+		return r, false, invalid
+	}
+	return r, empty, nil
+}
+
+// scoreReply replies with the score of m, or with the null bulk string if
+// the sorted set does not have it.
+func scoreReply(z *keyspace.ZSet, m string) respser.RespEncoder {
+	score, ok := z.Score(m)
+	if !ok {
+		return &respser.BulkString{}
+	}
+	return &respser.Double{F: score}
+}
@@ -0,0 +1,78 @@
+package main
+
+import "testing"
+
+func TestZsetCommands(t *testing.T) {
+	runCases(t, []commandCase{
+		{"zadd", []string{"ZADD z 1 a 2 b", "ZADD z 3 b 4 c"}, ":1\r\n"},
+		{"zadd_ch", []string{"ZADD z 1 a 2 b", "ZADD z CH 3 b 2 a 4 c"}, ":3\r\n"},
+		{"zadd_nx", []string{"ZADD z 1 a", "ZADD z NX 5 a", "ZSCORE z a"}, "$1\r\n1\r\n"},
+		{"zadd_xx", []string{"ZADD z XX 1 a", "EXISTS z"}, ":0\r\n"},
+		{"zadd_gt", []string{"ZADD z 5 a", "ZADD z GT 3 a", "ZSCORE z a"}, "$1\r\n5\r\n"},
+		{"zadd_lt", []string{"ZADD z 5 a", "ZADD z LT 3 a", "ZSCORE z a"}, "$1\r\n3\r\n"},
+		{"zadd_incr", []string{"ZADD z 5 a", "ZADD z INCR 2.5 a"}, "$3\r\n7.5\r\n"},
+		{"zadd_incr_nx_existing", []string{"ZADD z 5 a", "ZADD z NX INCR 2 a"}, "$-1\r\n"},
+		{"zadd_nx_xx", []string{"ZADD z NX XX 1 a"}, "-ERR XX and NX options at the same time are not compatible\r\n"},
+		{"zadd_gt_lt", []string{"ZADD z GT LT 1 a"}, "-ERR GT, LT, and/or NX options at the same time are not compatible\r\n"},
+		{"zadd_incr_pairs", []string{"ZADD z INCR 1 a 2 b"}, "-ERR INCR option supports a single increment-element pair\r\n"},
+		{"zadd_bad_score", []string{"ZADD z x a"}, "-ERR value is not a valid float\r\n"},
+		{"zadd_nan", []string{"ZADD z nan a"}, "-ERR value is not a valid float\r\n"},
+		{"zadd_overflow", []string{"ZADD z 1e400 a"}, "-ERR value is not a valid float\r\n"},
+		{"zadd_underflow", []string{"ZADD z 1e-400 a", "EXISTS z"}, ":0\r\n"},
+		{"zadd_denormal", []string{"ZADD z 5e-324 a", "ZSCORE z a"}, "$6\r\n5e-324\r\n"},
+		{"zadd_inf", []string{"ZADD z +inf a", "ZSCORE z a"}, "$3\r\ninf\r\n"},
+		{"zadd_odd_args", []string{"ZADD z 1 a 2"}, "-ERR syntax error\r\n"},
+		{"zadd_wrong_type", []string{"SET z v", "ZADD z 1 a"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"zincrby", []string{"ZINCRBY z 2 a", "ZINCRBY z -0.5 a"}, "$3\r\n1.5\r\n"},
+		{"zincrby_nan", []string{"ZADD z inf a", "ZINCRBY z -inf a"}, "-ERR resulting score is not a number (NaN)\r\n"},
+		{"zrem", []string{"ZADD z 1 a 2 b", "ZREM z a c"}, ":1\r\n"},
+		{"zrem_last_deletes", []string{"ZADD z 1 a", "ZREM z a", "EXISTS z"}, ":0\r\n"},
+		{"zscore_missing", []string{"ZADD z 1 a", "ZSCORE z b"}, "$-1\r\n"},
+		{"zscore_inf", []string{"ZADD z -inf a", "ZSCORE z a"}, "$4\r\n-inf\r\n"},
+		{"zscore_million", []string{"ZADD z 1000000 a", "ZSCORE z a"}, "$7\r\n1000000\r\n"},
+		{"zscore_large_fraction", []string{"ZADD z 1234567.5 a", "ZSCORE z a"}, "$9\r\n1234567.5\r\n"},
+		{"zscore_large", []string{"ZADD z 1e20 a", "ZSCORE z a"}, "$5\r\n1e+20\r\n"},
+		{"zscore_small", []string{"ZADD z 0.00001 a", "ZSCORE z a"}, "$7\r\n0.00001\r\n"},
+		{"zscore_tiny", []string{"ZADD z 1.5e-9 a", "ZSCORE z a"}, "$6\r\n1.5e-9\r\n"},
+		{"zmscore", []string{"ZADD z 1 a", "ZMSCORE z a b"}, "*2\r\n$1\r\n1\r\n$-1\r\n"},
+		{"zcard", []string{"ZADD z 1 a 2 b", "ZCARD z"}, ":2\r\n"},
+		{"zcount", []string{"ZADD z 1 a 2 b 3 c", "ZCOUNT z (1 +inf"}, ":2\r\n"},
+		{"zcount_bad_range", []string{"ZADD z 1 a", "ZCOUNT z x 1"}, "-ERR min or max is not a float\r\n"},
+		{"zcount_overflowing_range", []string{"ZADD z 1 a", "ZCOUNT z -inf 1e400"}, "-ERR min or max is not a float\r\n"},
+		{"zrank", []string{"ZADD z 1 a 2 b 3 c", "ZRANK z b"}, ":1\r\n"},
+		{"zrank_withscore", []string{"ZADD z 1 a 2.5 b", "ZRANK z b WITHSCORE"}, "*2\r\n:1\r\n$3\r\n2.5\r\n"},
+		{"zrevrank", []string{"ZADD z 1 a 2 b 3 c", "ZREVRANK z a"}, ":2\r\n"},
+		{"zrank_missing", []string{"ZADD z 1 a", "ZRANK z b"}, "$-1\r\n"},
+		{"zrank_same_score_by_member", []string{"ZADD z 1 c 1 a 1 b", "ZRANK z c"}, ":2\r\n"},
+	})
+}
+
+func TestZrange(t *testing.T) {
+	add := "ZADD z 1 a 2 b 3 c 4 d"
+	runCases(t, []commandCase{
+		{"by_rank", []string{add, "ZRANGE z 1 -2"}, "*2\r\n$1\r\nb\r\n$1\r\nc\r\n"},
+		{"by_rank_rev", []string{add, "ZRANGE z 0 1 REV"}, "*2\r\n$1\r\nd\r\n$1\r\nc\r\n"},
+		{"zrevrange", []string{add, "ZREVRANGE z -1 -1"}, "*1\r\n$1\r\na\r\n"},
+		{"by_rank_empty", []string{add, "ZRANGE z 3 1"}, "*0\r\n"},
+		{"withscores", []string{add, "ZRANGE z 0 0 WITHSCORES"}, "*2\r\n$1\r\na\r\n$1\r\n1\r\n"},
+		{"withscores_large", []string{"ZADD z 1e6 a 1234567.5 b 1e20 c", "ZRANGE z 0 -1 WITHSCORES"},
+			"*6\r\n$1\r\na\r\n$7\r\n1000000\r\n$1\r\nb\r\n$9\r\n1234567.5\r\n$1\r\nc\r\n$5\r\n1e+20\r\n"},
+		{"withscores_small", []string{"ZADD z 1e-5 a 1e-6 b 1e-7 c", "ZRANGE z 0 -1 WITHSCORES"},
+			"*6\r\n$1\r\nc\r\n$4\r\n1e-7\r\n$1\r\nb\r\n$8\r\n0.000001\r\n$1\r\na\r\n$7\r\n0.00001\r\n"},
+		{"withscores_resp3", []string{"HELLO 3", "ZADD z 1e6 a", "ZRANGE z 0 -1 WITHSCORES"}, "*1\r\n*2\r\n$1\r\na\r\n,1000000\r\n"},
+		{"by_score", []string{add, "ZRANGE z (1 3 BYSCORE"}, "*2\r\n$1\r\nb\r\n$1\r\nc\r\n"},
+		{"by_score_rev", []string{add, "ZRANGE z +inf 3 BYSCORE REV"}, "*2\r\n$1\r\nd\r\n$1\r\nc\r\n"},
+		{"by_score_limit", []string{add, "ZRANGE z -inf +inf BYSCORE LIMIT 1 2"}, "*2\r\n$1\r\nb\r\n$1\r\nc\r\n"},
+		{"zrangebyscore_withscores", []string{add, "ZRANGEBYSCORE z 4 4 WITHSCORES"}, "*2\r\n$1\r\nd\r\n$1\r\n4\r\n"},
+		{"zrevrangebyscore", []string{add, "ZREVRANGEBYSCORE z 2 -inf"}, "*2\r\n$1\r\nb\r\n$1\r\na\r\n"},
+		{"by_score_bad_range", []string{add, "ZRANGE z (x 3 BYSCORE"}, "-ERR min or max is not a float\r\n"},
+		{"by_lex", []string{"ZADD z 0 a 0 b 0 c 0 d", "ZRANGE z [b (d BYLEX"}, "*2\r\n$1\r\nb\r\n$1\r\nc\r\n"},
+		{"by_lex_open", []string{"ZADD z 0 a 0 b 0 c", "ZRANGE z - + BYLEX LIMIT 1 1"}, "*1\r\n$1\r\nb\r\n"},
+		{"zrevrangebylex", []string{"ZADD z 0 a 0 b 0 c", "ZREVRANGEBYLEX z + (a"}, "*2\r\n$1\r\nc\r\n$1\r\nb\r\n"},
+		{"by_lex_bad_range", []string{"ZADD z 0 a", "ZRANGE z a c BYLEX"}, "-ERR min or max not valid string range item\r\n"},
+		{"limit_without_by", []string{add, "ZRANGE z 0 -1 LIMIT 0 1"}, "-ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX\r\n"},
+		{"withscores_by_lex", []string{add, "ZRANGE z - + BYLEX WITHSCORES"}, "-ERR syntax error, WITHSCORES not supported in combination with BYLEX\r\n"},
+		{"bad_option", []string{add, "ZRANGE z 0 -1 FOO"}, "-ERR syntax error\r\n"},
+		{"missing_key", []string{"ZRANGE z 0 -1"}, "*0\r\n"},
+	})
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"gored/keyspace"
+	"gored/respser"
+)
+
+type commandFlags uint
+
+const (
+	flagWrite    commandFlags = 1 << iota // may modify the keyspace
+	flagReadonly                          // only reads the keyspace
+	flagFast                              // runs in constant or logarithmic time
+	flagBlocking                          // may block the client
+	flagNoQueue                           // runs at once inside MULTI instead of being queued
+)
+
+// commandFunc runs a command. args is the whole command line, so args[0] is
+// the command name as the client sent it.
+type commandFunc func(c *client, args []string) respser.RespEncoder
+
+// command describes a command the way the Redis command table does.
+type command struct {
+	name    string
+	handler commandFunc
+	// arity is the exact number of arguments, name included, or the
+	// negated minimum for commands taking a variable number.
+	arity int
+	flags commandFlags
+	// firstKey, lastKey and step locate the keys among the arguments.
+	// lastKey is negative when counted from the end, and firstKey is 0 for
+	// commands without keys.
+	firstKey, lastKey, step int
+}
+
+var commands = map[string]*command{}
+
+// registerCommand adds a command to the table. It is meant to be called from
+// init functions and panics on duplicate names.
+func registerCommand(name string, handler commandFunc, arity int, flags commandFlags, firstKey, lastKey, step int) {
+	name = strings.ToUpper(name)
+	if _, ok := commands[name]; ok {
+		panic("command registered twice: " + name)
+	}
+	commands[name] = &command{
+		name:     name,
+		handler:  handler,
+		arity:    arity,
+		flags:    flags,
+		firstKey: firstKey,
+		lastKey:  lastKey,
+		step:     step,
+	}
+}
+
+func lookupCommand(name string) *command {
+	return commands[strings.ToUpper(name)]
+}
+
+func (cmd *command) checkArity(argc int) bool {
+	if cmd.arity >= 0 {
+		return argc == cmd.arity
+	}
+	return argc >= -cmd.arity
+}
+
+// keys returns the key arguments of a command line that passed checkArity.
+func (cmd *command) keys(args []string) []string {
+	if cmd.firstKey == 0 {
+		return nil
+	}
+	last := cmd.lastKey
+	if last < 0 {
+		last += len(args)
+	}
+	keys := []string{}
+	for i := cmd.firstKey; i <= last && i < len(args); i += cmd.step {
+		keys = append(keys, args[i])
+	}
+	return keys
+}
+
+func handleCommand(c *client, args []string) respser.RespEncoder {
+	// Like Redis, an empty request gets no reply.
+	if len(args) == 0 {
+		return nil
+	}
+
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		c.multiError = c.inMulti
+		return unknownCommandReply(args)
+	}
+	if !cmd.checkArity(len(args)) {
+		c.multiError = c.inMulti
+		return wrongArityReply(cmd.name)
+	}
+	if c.inMulti && cmd.flags&flagNoQueue == 0 {
+		c.queued = append(c.queued, args)
+		return &respser.SimpleString{S: "QUEUED"}
+	}
+
+	reply := call(c, cmd, args)
+	for c.waiter != nil {
+		reply = c.waitBlocked(cmd, args)
+	}
+	return reply
+}
+
+// call runs a command with the locks it needs held.
+func call(c *client, cmd *command, args []string) respser.RespEncoder {
+	unlock := lockKeys(c.db, [][]string{args})
+	defer unlock()
+	reply := cmd.handler(c, args)
+	// A blocked command that got a reply at last is done waiting.
+	if reply != nil && c.waiter != nil {
+		c.db.Unblock(c.waiter)
+		c.waiter = nil
+	}
+	return reply
+}
+
+// lockKeys locks what the command lines need to run atomically: commands
+// that touch the keyspace lock the keys they name, or all of it if they
+// name none.
+func lockKeys(db *keyspace.DB, lines [][]string) (unlock func()) {
+	var keys []string
+	for _, args := range lines {
+		cmd := lookupCommand(args[0])
+		if cmd.flags&(flagWrite|flagReadonly) == 0 {
+			continue
+		}
+		k := cmd.keys(args)
+		if len(k) == 0 {
+			return db.LockAll()
+		}
+		keys = append(keys, k...)
+	}
+	return db.Lock(keys...)
+}
+
+// parseInt64 parses an integer argument as strictly as Redis does: no sign
+// other than a leading '-', no leading zeros and no surrounding spaces.
+func parseInt64(s string) (int64, bool) {
+	if s == "" || s == "-" || s[0] == '+' {
+		return 0, false
+	}
+	digits := strings.TrimPrefix(s, "-")
+	if digits[0] == '0' && s != "0" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil
+}
+
+// parseFloat parses a float argument. Spaces, NaN and values too large or
+// too small for a float64 are rejected; infinity spelled out is accepted,
+// as in Redis.
+func parseFloat(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) {
+		return 0, false
+	}
+	// ParseFloat rounds values too small for a float64 to zero without an
+	// error.
+	if f == 0 {
+		if b, ok := new(big.Float).SetString(s); ok && b.Sign() != 0 {
+			return 0, false
+		}
+	}
+	return f, true
+}
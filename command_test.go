@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"gored/keyspace"
+	"gored/respser"
+)
+
+// commandCase runs cmds, one command line each, and expects the RESP2
+// encoding of the last reply to be want.
+type commandCase struct {
+	name string
+	cmds []string
+	want string
+}
+
+func newTestClient() *client {
+	return &client{
+		db:       keyspace.New(),
+		cfg:      &config{limits: respser.DefaultLimits},
+		protocol: 2,
+	}
+}
+
+// run runs the command lines, split as inline commands are, through
+// handleCommand as c, and returns the encoding of the last reply.
+func run(t *testing.T, c *client, lines ...string) string {
+	t.Helper()
+	var reply respser.RespEncoder
+	for _, line := range lines {
+		args, err := respser.SplitArgs(line)
+		if err != nil {
+			t.Fatalf("Expected a command line, Got %q: %v", line, err)
+		}
+		reply = handleCommand(c, args)
+	}
+	return respser.ToProtocol(reply, c.protocol).RespEncode()
+}
+
+// runCases runs each case as a new client on a fresh DB.
+func runCases(t *testing.T, testCases []commandCase) {
+	t.Helper()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := run(t, newTestClient(), tc.cmds...); got != tc.want {
+				t.Errorf("Expected %q, Got %q", tc.want, got)
+			}
+		})
+	}
+}
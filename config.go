@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+
+	"gored/respser"
+)
+
+// config holds the server settings. Flag names follow the matching
+// redis.conf directives where one exists.
+type config struct {
+	port   int
+	limits respser.Limits
+	// hz is how many times per second background tasks such as active
+	// expiration run.
+	hz int
+}
+
+func parseConfig(args []string) (*config, error) {
+	cfg := &config{limits: respser.DefaultLimits, hz: 10}
+
+	fs := flag.NewFlagSet("gored", flag.ContinueOnError)
+	fs.IntVar(&cfg.port, "port", 6380, "TCP port to listen on")
+	fs.IntVar(&cfg.limits.MaxBulkLen, "proto-max-bulk-len", cfg.limits.MaxBulkLen, "largest bulk string a client may send, in bytes")
+	fs.IntVar(&cfg.limits.MaxMultibulkLen, "proto-max-multibulk-len", cfg.limits.MaxMultibulkLen, "most arguments a single command may have")
+	fs.IntVar(&cfg.limits.MaxDepth, "proto-max-nesting", cfg.limits.MaxDepth, "deepest nesting of aggregates a client may send")
+	fs.IntVar(&cfg.limits.MaxInlineLen, "proto-inline-max-size", cfg.limits.MaxInlineLen, "longest inline command or protocol line, in bytes")
+	fs.IntVar(&cfg.hz, "hz", cfg.hz, "how many times per second background tasks run")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	// Like Redis, keep hz within what the server can sensibly honor.
+	cfg.hz = min(max(cfg.hz, 1), 500)
+	return cfg, nil
+}
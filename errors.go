@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gored/respser"
+)
+
+// Error replies shared by several commands. Client libraries map replies to
+// exceptions by their wording, so it follows Redis exactly.
+const (
+	errWrongType  = "WRONGTYPE Operation against a key holding the wrong kind of value"
+	errSyntax     = "ERR syntax error"
+	errNotInteger = "ERR value is not an integer or out of range"
+)
+
+// errorReply formats an error reply. A line break would end the reply early,
+// so line breaks are replaced by spaces as Redis does.
+func errorReply(format string, a ...any) *respser.ErrorString {
+	s := fmt.Sprintf(format, a...)
+	return &respser.ErrorString{E: strings.NewReplacer("\r", " ", "\n", " ").Replace(s)}
+}
+
+func unknownCommandReply(args []string) *respser.ErrorString {
+	var sb strings.Builder
+	for _, arg := range args[1:] {
+		if sb.Len() >= 128 {
+			break
+		}
+		fmt.Fprintf(&sb, "'%.*s' ", 128-sb.Len(), arg)
+	}
+	return errorReply("ERR unknown command '%.128s', with args beginning with: %s", args[0], sb.String())
+}
+
+func wrongArityReply(name string) *respser.ErrorString {
+	return errorReply("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+}
+
+// protocolError is input that cannot be read as a command. The client is
+// told what was wrong and the connection is closed, since nothing after the
+// bad input can be trusted to start a new command.
+type protocolError struct {
+	msg string
+}
+
+func (e *protocolError) Error() string {
+	return "Protocol error: " + e.msg
+}
+
+// asProtocolError returns the protocol error behind a failed read, or nil
+// if the read failed for another reason such as the client going away.
+func asProtocolError(err error) *protocolError {
+	var perr *protocolError
+	if errors.As(err, &perr) {
+		return perr
+	}
+	var rse *respser.RespSerError
+	if !errors.As(err, &rse) {
+		return nil
+	}
+	switch {
+	case errors.Is(rse, respser.ErrUnbalancedQuotes):
+		return &protocolError{"unbalanced quotes in request"}
+	case errors.Is(rse, respser.ErrInlineTooLong):
+		return &protocolError{"too big inline request"}
+	case errors.Is(rse, respser.ErrMultibulkLengthExceeded):
+		return &protocolError{"invalid multibulk length"}
+	case errors.Is(rse, respser.ErrBulkLengthExceeded):
+		return &protocolError{"invalid bulk length"}
+	case errors.Is(rse, respser.ErrNestingTooDeep):
+		return &protocolError{fmt.Sprintf("expected '$', got '%c'", rse.In[0])}
+	case strings.HasPrefix(rse.In, "*"):
+		return &protocolError{"invalid multibulk length"}
+	case strings.HasPrefix(rse.In, "$"):
+		return &protocolError{"invalid bulk length"}
+	case rse.In != "":
+		return &protocolError{fmt.Sprintf("expected '$', got '%c'", rse.In[0])}
+	}
+	return &protocolError{rse.Err.Error()}
+}
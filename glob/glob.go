@@ -0,0 +1,88 @@
+// Package glob matches strings against the glob-style patterns of Redis
+// commands such as KEYS and SCAN.
+package glob
+
+// Match reports whether s matches pattern, in which
+//
+//	'*'      matches any sequence of bytes, including none
+//	'?'      matches any single byte
+//	'[abc]'  matches one of the bytes listed
+//	'[^abc]' matches any byte not listed
+//	'[a-z]'  matches a byte in the range, whose ends may come in either order
+//	'\x'     matches x literally, here and inside brackets
+//
+// As in Redis, a pattern is never malformed: an unterminated bracket runs
+// to the end of the pattern and a trailing backslash matches itself.
+func Match(pattern, s string) bool {
+	p, i := 0, 0
+	// Where to resume after the last star: the pattern after it, and the
+	// next byte of s for it to swallow. Only the last star ever needs to be
+	// backtracked to, which keeps matching linear in the pattern length
+	// for each byte of s.
+	star, next := -1, 0
+	for i < len(s) {
+		if p < len(pattern) {
+			if pattern[p] == '*' {
+				p++
+				star, next = p, i
+				continue
+			}
+			if n, ok := matchOne(pattern[p:], s[i]); ok {
+				p += n
+				i++
+				continue
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		next++
+		p, i = star, next
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchOne matches c against the element pattern starts with, which is not
+// a star, and returns the length of the element.
+func matchOne(pattern string, c byte) (int, bool) {
+	switch pattern[0] {
+	case '?':
+		return 1, true
+	case '[':
+		return matchClass(pattern, c)
+	case '\\':
+		if len(pattern) >= 2 {
+			return 2, pattern[1] == c
+		}
+	}
+	return 1, pattern[0] == c
+}
+
+func matchClass(pattern string, c byte) (int, bool) {
+	i := 1
+	not := i < len(pattern) && pattern[i] == '^'
+	if not {
+		i++
+	}
+	match := false
+	for ; i < len(pattern) && pattern[i] != ']'; i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			match = match || pattern[i] == c
+		case i+2 < len(pattern) && pattern[i+1] == '-':
+			lo, hi := pattern[i], pattern[i+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			match = match || lo <= c && c <= hi
+			i += 2
+		default:
+			match = match || pattern[i] == c
+		}
+	}
+	return min(i+1, len(pattern)), match != not
+}
@@ -0,0 +1,55 @@
+package glob_test
+
+import (
+	"gored/glob"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"literal", "hello", "hello", true},
+		{"literal_mismatch", "hello", "hellO", false},
+		{"literal_prefix", "hello", "hell", false},
+		{"empty", "", "", true},
+		{"empty_pattern", "", "a", false},
+		{"star_matches_all", "*", "anything", true},
+		{"star_matches_empty", "*", "", true},
+		{"star_prefix", "user:*", "user:1000", true},
+		{"star_prefix_mismatch", "user:*", "session:1", false},
+		{"star_middle", "h*llo", "heeeello", true},
+		{"star_backtrack", "*a*b", "aaacab", true},
+		{"star_backtrack_mismatch", "*a*b", "aaacba", false},
+		{"stars_collapse", "a**b", "axyb", true},
+		{"question", "h?llo", "hallo", true},
+		{"question_needs_byte", "h?llo", "hllo", false},
+		{"class", "h[ae]llo", "hello", true},
+		{"class_mismatch", "h[ae]llo", "hillo", false},
+		{"class_negated", "h[^e]llo", "hallo", true},
+		{"class_negated_mismatch", "h[^e]llo", "hello", false},
+		{"class_range", "h[a-b]llo", "hbllo", true},
+		{"class_range_reversed", "h[b-a]llo", "hallo", true},
+		{"class_range_mismatch", "h[a-b]llo", "hcllo", false},
+		{"class_escape", `[\]]`, "]", true},
+		{"class_unterminated", "[ab", "b", true},
+		{"escape_star", `a\*`, "a*", true},
+		{"escape_star_mismatch", `a\*`, "ab", false},
+		{"escape_question", `\?`, "x", false},
+		{"trailing_backslash", `a\`, `a\`, true},
+		{"binary", "\x00*\xff", "\x00abc\xff", true},
+		{"many_stars", strings.Repeat("*a", 30) + "b", strings.Repeat("a", 60), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := glob.Match(tc.pattern, tc.s); got != tc.want {
+				t.Errorf("Expected %v, Got %v", tc.want, got)
+			}
+		})
+	}
+}
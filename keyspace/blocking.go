@@ -0,0 +1,72 @@
+package keyspace
+
+// Waiter is a client blocked until a list is stored at one of its keys,
+// as BLPOP is. Waiters are queued per key and woken one at a time in the
+// order they blocked: a key wakes the first of its waiters, and the next
+// one is only woken when that one unblocks and leaves a list behind.
+type Waiter struct {
+	keys  []string
+	ready chan struct{}
+}
+
+// Keys returns the keys w waits for.
+func (w *Waiter) Keys() []string {
+	return w.keys
+}
+
+// Ready receives when one of the keys may hold a list. The waiter still
+// has to look: another client can get there first, in which case it
+// stays queued and waits for Ready again.
+func (w *Waiter) Ready() <-chan struct{} {
+	return w.ready
+}
+
+func (w *Waiter) wake() {
+	select {
+	case w.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Block queues a new Waiter for keys, which must be locked. Queueing while
+// holding the locks means no list can be stored at the keys unnoticed in
+// between the caller finding them empty and the Waiter being queued.
+func (db *DB) Block(keys ...string) *Waiter {
+	w := &Waiter{keys: keys, ready: make(chan struct{}, 1)}
+	for _, key := range keys {
+		sh := db.shard(key)
+		sh.blocked[key] = append(sh.blocked[key], w)
+	}
+	return w
+}
+
+// Unblock removes w from the queues of its keys, which must be locked, and
+// wakes the next waiter for the keys that still hold a list.
+func (db *DB) Unblock(w *Waiter) {
+	for _, key := range w.keys {
+		sh := db.shard(key)
+		queue := sh.blocked[key]
+		for i, o := range queue {
+			if o == w {
+				queue = append(queue[:i], queue[i+1:]...)
+				break
+			}
+		}
+		if len(queue) == 0 {
+			delete(sh.blocked, key)
+			continue
+		}
+		sh.blocked[key] = queue
+		if v, _ := sh.dict.Get(key); v != nil && v.Type() == TypeList {
+			queue[0].wake()
+		}
+	}
+}
+
+// signalReady wakes the first waiter for key, after a list was stored
+// there.
+func (db *DB) signalReady(key string) {
+	if queue := db.shard(key).blocked[key]; len(queue) > 0 {
+		queue[0].wake()
+	}
+}
@@ -0,0 +1,71 @@
+package keyspace_test
+
+import (
+	"gored/keyspace"
+	"testing"
+)
+
+func woken(w *keyspace.Waiter) bool {
+	select {
+	case <-w.Ready():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestBlock(t *testing.T) {
+	testCases := []struct {
+		name string
+		// modify runs with waiters a and b queued, a first, and returns
+		// which of them are expected to be woken.
+		modify func(db *keyspace.DB, a, b *keyspace.Waiter) (bool, bool)
+	}{
+		{"nothing_stored", func(db *keyspace.DB, a, b *keyspace.Waiter) (bool, bool) {
+			return false, false
+		}},
+		{"string_stored", func(db *keyspace.DB, a, b *keyspace.Waiter) (bool, bool) {
+			db.Set("k", keyspace.String("v"))
+			return false, false
+		}},
+		{"list_wakes_first", func(db *keyspace.DB, a, b *keyspace.Waiter) (bool, bool) {
+			db.Set("k", keyspace.NewList())
+			return true, false
+		}},
+		{"unblock_passes_list_on", func(db *keyspace.DB, a, b *keyspace.Waiter) (bool, bool) {
+			db.Set("k", keyspace.NewList())
+			<-a.Ready()
+			db.Unblock(a)
+			return false, true
+		}},
+		{"unblock_without_list", func(db *keyspace.DB, a, b *keyspace.Waiter) (bool, bool) {
+			db.Unblock(a)
+			return false, false
+		}},
+		{"unblocked_is_not_woken", func(db *keyspace.DB, a, b *keyspace.Waiter) (bool, bool) {
+			db.Unblock(a)
+			db.Set("k", keyspace.NewList())
+			return false, true
+		}},
+		{"other_key", func(db *keyspace.DB, a, b *keyspace.Waiter) (bool, bool) {
+			db.Set("other", keyspace.NewList())
+			return false, true
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := keyspace.New()
+			a := db.Block("k")
+			b := db.Block("k", "other")
+			wantA, wantB := tc.modify(db, a, b)
+
+			if got := woken(a); got != wantA {
+				t.Errorf("Expected a woken %v, Got %v", wantA, got)
+			}
+			if got := woken(b); got != wantB {
+				t.Errorf("Expected b woken %v, Got %v", wantB, got)
+			}
+		})
+	}
+}
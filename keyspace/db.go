@@ -0,0 +1,407 @@
+package keyspace
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// shardCount is the number of independently locked parts of a DB. It must
+// not exceed 64, the width of the masks Lock works with.
+const shardCount = 16
+
+// DB is a keyspace: a map from keys to values, plus the expire time of the
+// keys that have one. Keys are spread over shards that are locked
+// separately, so commands on unrelated keys do not wait for each other.
+// The methods do no locking of their own; callers hold the locks returned
+// by Lock or LockAll for the duration of a command, which makes commands
+// atomic with respect to each other.
+type DB struct {
+	shards [shardCount]shard
+	clock  Clock
+}
+
+type shard struct {
+	mu      sync.Mutex
+	dict    *Dict[Value]
+	expires map[string]int64 // unix time in milliseconds
+	blocked map[string][]*Waiter
+	// volatileHashes holds the keys of hashes with fields that have an
+	// expire time, for ActiveExpireCycle to look at.
+	volatileHashes map[string]struct{}
+}
+
+// Clock tells a DB the time, which decides when keys expire.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func New() *DB {
+	db := &DB{clock: systemClock{}}
+	for i := range db.shards {
+		db.shards[i].dict = NewDict[Value]()
+		db.shards[i].expires = map[string]int64{}
+		db.shards[i].blocked = map[string][]*Waiter{}
+		db.shards[i].volatileHashes = map[string]struct{}{}
+	}
+	return db
+}
+
+// SetClock replaces the clock of the DB, which starts out as the system
+// clock. It exists so that expiration can be tested without waiting.
+func (db *DB) SetClock(clock Clock) {
+	db.clock = clock
+}
+
+// Lock locks the shards holding keys and returns a function that unlocks
+// them. Shards are always locked in the same order, so commands locking
+// overlapping keys cannot deadlock.
+func (db *DB) Lock(keys ...string) (unlock func()) {
+	var mask uint64
+	for _, key := range keys {
+		mask |= 1 << shardIndex(key)
+	}
+	return db.lockShards(mask)
+}
+
+// LockAll locks the whole DB, for commands that look at every key.
+func (db *DB) LockAll() (unlock func()) {
+	return db.lockShards(1<<shardCount - 1)
+}
+
+func (db *DB) lockShards(mask uint64) func() {
+	for i := range db.shards {
+		if mask&(1<<i) != 0 {
+			db.shards[i].mu.Lock()
+		}
+	}
+	return func() {
+		for i := len(db.shards) - 1; i >= 0; i-- {
+			if mask&(1<<i) != 0 {
+				db.shards[i].mu.Unlock()
+			}
+		}
+	}
+}
+
+// shardIndex hashes key with FNV-1a.
+func shardIndex(key string) int {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return int(h % shardCount)
+}
+
+func (db *DB) shard(key string) *shard {
+	return &db.shards[shardIndex(key)]
+}
+
+// Now returns the current time in unix milliseconds, the unit expire times
+// are kept in.
+func (db *DB) Now() int64 {
+	return db.clock.Now().UnixMilli()
+}
+
+// Get returns the value stored at key. A key whose expire time has passed is
+// deleted instead, and so are the expired fields of a hash, along with the
+// key if no fields are left.
+func (db *DB) Get(key string) (Value, bool) {
+	if db.expireIfNeeded(key) {
+		return nil, false
+	}
+	v, ok := db.shard(key).dict.Get(key)
+	if h, isHash := v.(*Hash); isHash && h.expireFields(db.Now()) > 0 && h.Len() == 0 {
+		db.remove(key)
+		return nil, false
+	}
+	return v, ok
+}
+
+// GetString returns the string stored at key, or ErrWrongType if key holds
+// another type.
+func (db *DB) GetString(key string) (String, bool, error) {
+	v, ok := db.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	s, ok := v.(String)
+	if !ok {
+		return nil, false, ErrWrongType
+	}
+	return s, true, nil
+}
+
+// GetList returns the list stored at key, or ErrWrongType if key holds
+// another type.
+func (db *DB) GetList(key string) (*List, bool, error) {
+	v, ok := db.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	l, ok := v.(*List)
+	if !ok {
+		return nil, false, ErrWrongType
+	}
+	return l, true, nil
+}
+
+// GetHash returns the hash stored at key, or ErrWrongType if key holds
+// another type.
+func (db *DB) GetHash(key string) (*Hash, bool, error) {
+	v, ok := db.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	h, ok := v.(*Hash)
+	if !ok {
+		return nil, false, ErrWrongType
+	}
+	return h, true, nil
+}
+
+// GetSet returns the set stored at key, or ErrWrongType if key holds
+// another type.
+func (db *DB) GetSet(key string) (*Set, bool, error) {
+	v, ok := db.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	s, ok := v.(*Set)
+	if !ok {
+		return nil, false, ErrWrongType
+	}
+	return s, true, nil
+}
+
+// GetZSet returns the sorted set stored at key, or ErrWrongType if key
+// holds another type.
+func (db *DB) GetZSet(key string) (*ZSet, bool, error) {
+	v, ok := db.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	z, ok := v.(*ZSet)
+	if !ok {
+		return nil, false, ErrWrongType
+	}
+	return z, true, nil
+}
+
+func (db *DB) Exists(key string) bool {
+	_, ok := db.Get(key)
+	return ok
+}
+
+// Set stores v at key and removes any expire time, as a plain SET does.
+// Storing a list wakes a client blocked on key.
+func (db *DB) Set(key string, v Value) {
+	sh := db.shard(key)
+	sh.dict.Set(key, v)
+	delete(sh.expires, key)
+	switch v := v.(type) {
+	case *List:
+		db.signalReady(key)
+	case *Hash:
+		if v.volatile() {
+			sh.volatileHashes[key] = struct{}{}
+		}
+	}
+}
+
+// Update stores v at key and keeps its expire time, for commands that
+// modify a value in place.
+func (db *DB) Update(key string, v Value) {
+	db.shard(key).dict.Set(key, v)
+}
+
+// Delete removes key and reports whether it existed.
+func (db *DB) Delete(key string) bool {
+	if !db.Exists(key) {
+		return false
+	}
+	db.remove(key)
+	return true
+}
+
+// Expire returns the expire time of key, if it has one.
+func (db *DB) Expire(key string) (int64, bool) {
+	at, ok := db.shard(key).expires[key]
+	return at, ok
+}
+
+// SetExpire sets the expire time of an existing key. A time that is not in
+// the future deletes the key at once.
+func (db *DB) SetExpire(key string, at int64) {
+	sh := db.shard(key)
+	if _, ok := sh.dict.Get(key); !ok {
+		return
+	}
+	if at <= db.Now() {
+		db.remove(key)
+		return
+	}
+	sh.expires[key] = at
+}
+
+// Persist removes the expire time of key and reports whether it had one.
+func (db *DB) Persist(key string) bool {
+	sh := db.shard(key)
+	if _, ok := sh.expires[key]; !ok {
+		return false
+	}
+	delete(sh.expires, key)
+	return true
+}
+
+// SetFieldExpire sets the expire time of field in the hash at key, which
+// must both exist. A time that is not in the future deletes the field at
+// once, and the key too if it was the last field.
+func (db *DB) SetFieldExpire(key, field string, at int64) {
+	sh := db.shard(key)
+	v, _ := sh.dict.Get(key)
+	h := v.(*Hash)
+	if at <= db.Now() {
+		h.Delete(field)
+		if h.Len() == 0 {
+			db.remove(key)
+		}
+		return
+	}
+	h.setExpire(field, at)
+	sh.volatileHashes[key] = struct{}{}
+}
+
+// Rename moves the value and expire time of src to dst, replacing whatever
+// dst held, and reports whether src existed.
+func (db *DB) Rename(src, dst string) bool {
+	v, ok := db.Get(src)
+	if !ok {
+		return false
+	}
+	at, volatile := db.Expire(src)
+	db.remove(src)
+	db.Set(dst, v)
+	if volatile {
+		db.shard(dst).expires[dst] = at
+	}
+	return true
+}
+
+// Copy stores a copy of the value and expire time of src at dst, replacing
+// whatever dst held, and reports whether src existed.
+func (db *DB) Copy(src, dst string) bool {
+	v, ok := db.Get(src)
+	if !ok {
+		return false
+	}
+	at, volatile := db.Expire(src)
+	db.Set(dst, v.Clone())
+	if volatile {
+		db.shard(dst).expires[dst] = at
+	}
+	return true
+}
+
+// RandomKey returns a random key that has not expired. It needs the whole
+// DB locked.
+func (db *DB) RandomKey() (string, bool) {
+	for db.Len() > 0 {
+		// Pick a shard with a chance proportional to its size.
+		n := rand.Intn(db.Len())
+		i := 0
+		for n >= db.shards[i].dict.Len() {
+			n -= db.shards[i].dict.Len()
+			i++
+		}
+		key, _, _ := db.shards[i].dict.Random()
+		if !db.expireIfNeeded(key) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// Len returns the number of keys, including expired keys not yet deleted.
+// It needs the whole DB locked.
+func (db *DB) Len() int {
+	n := 0
+	for i := range db.shards {
+		n += db.shards[i].dict.Len()
+	}
+	return n
+}
+
+// Range calls fn for every key that has not expired, until fn returns
+// false. It needs the whole DB locked, and fn must not modify the DB.
+func (db *DB) Range(fn func(key string, v Value) bool) {
+	for i := range db.shards {
+		more := true
+		db.shards[i].dict.Range(func(key string, v Value) bool {
+			if !db.expired(key) {
+				more = fn(key, v)
+			}
+			return more
+		})
+		if !more {
+			return
+		}
+	}
+}
+
+// Scan calls fn for the keys found in one step of an iteration over the
+// whole DB, and returns the cursor for the next step, which is 0 once the
+// iteration is done. As with Dict.Scan, an iteration started with cursor 0
+// reports every key that exists throughout, however the DB changes in
+// between the steps. Expired keys are deleted instead of reported. It
+// needs the whole DB locked, and fn must not modify the DB.
+func (db *DB) Scan(cursor uint64, fn func(key string, v Value)) uint64 {
+	// The shard being scanned is kept in the low bits of the cursor.
+	i, cursor := cursor%shardCount, cursor/shardCount
+	var expired []string
+	cursor = db.shards[i].dict.Scan(cursor, func(key string, v Value) {
+		if db.expired(key) {
+			expired = append(expired, key)
+			return
+		}
+		fn(key, v)
+	})
+	for _, key := range expired {
+		db.remove(key)
+	}
+	if cursor != 0 {
+		return cursor*shardCount + i
+	}
+	if i+1 == shardCount {
+		return 0
+	}
+	return i + 1
+}
+
+// expireIfNeeded deletes key if its expire time has passed and reports
+// whether it did. This is the lazy half of expiration; ActiveExpireCycle
+// is the other.
+func (db *DB) expireIfNeeded(key string) bool {
+	if !db.expired(key) {
+		return false
+	}
+	db.remove(key)
+	return true
+}
+
+func (db *DB) expired(key string) bool {
+	at, ok := db.shard(key).expires[key]
+	return ok && at < db.Now()
+}
+
+func (db *DB) remove(key string) {
+	sh := db.shard(key)
+	sh.dict.Delete(key)
+	delete(sh.expires, key)
+	delete(sh.volatileHashes, key)
+}
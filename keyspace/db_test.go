@@ -0,0 +1,313 @@
+package keyspace_test
+
+import (
+	"errors"
+	"gored/keyspace"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestDB() (*keyspace.DB, *fakeClock) {
+	clock := &fakeClock{now: time.UnixMilli(1700000000000)}
+	db := keyspace.New()
+	db.SetClock(clock)
+	return db, clock
+}
+
+func TestGetString(t *testing.T) {
+	db := keyspace.New()
+	db.Set("s", keyspace.String("v"))
+	db.Set("l", keyspace.NewList())
+
+	testCases := []struct {
+		name  string
+		key   string
+		want  string
+		found bool
+		err   error
+	}{
+		{"get_string", "s", "v", true, nil},
+		{"get_missing", "missing", "", false, nil},
+		{"get_wrong_type", "l", "", false, keyspace.ErrWrongType},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, found, err := db.GetString(tc.key)
+
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Expected error %v, Got %v", tc.err, err)
+			}
+			if found != tc.found || string(got) != tc.want {
+				t.Errorf("Expected %q %v, Got %q %v", tc.want, tc.found, got, found)
+			}
+		})
+	}
+}
+
+func TestExpire(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(db *keyspace.DB)
+		exists bool
+		ttl    bool
+	}{
+		{"expire_in_future", func(db *keyspace.DB) { db.SetExpire("k", db.Now()+60000) }, true, true},
+		{"expire_in_past", func(db *keyspace.DB) { db.SetExpire("k", db.Now()-1) }, false, false},
+		{"set_clears_ttl", func(db *keyspace.DB) {
+			db.SetExpire("k", db.Now()+60000)
+			db.Set("k", keyspace.String("w"))
+		}, true, false},
+		{"update_keeps_ttl", func(db *keyspace.DB) {
+			db.SetExpire("k", db.Now()+60000)
+			db.Update("k", keyspace.String("w"))
+		}, true, true},
+		{"persist", func(db *keyspace.DB) {
+			db.SetExpire("k", db.Now()+60000)
+			db.Persist("k")
+		}, true, false},
+		{"delete", func(db *keyspace.DB) {
+			db.SetExpire("k", db.Now()+60000)
+			db.Delete("k")
+		}, false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := keyspace.New()
+			db.Set("k", keyspace.String("v"))
+			tc.modify(db)
+
+			if got := db.Exists("k"); got != tc.exists {
+				t.Errorf("Expected exists %v, Got %v", tc.exists, got)
+			}
+			if _, got := db.Expire("k"); got != tc.ttl {
+				t.Errorf("Expected ttl %v, Got %v", tc.ttl, got)
+			}
+		})
+	}
+}
+
+func TestLazyExpire(t *testing.T) {
+	testCases := []struct {
+		name    string
+		ttl     time.Duration
+		advance time.Duration
+		exists  bool
+	}{
+		{"before_expire_time", time.Second, 999 * time.Millisecond, true},
+		{"at_expire_time", time.Second, time.Second, true},
+		{"after_expire_time", time.Second, time.Second + time.Millisecond, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, clock := newTestDB()
+			db.Set("k", keyspace.String("v"))
+			db.SetExpire("k", db.Now()+tc.ttl.Milliseconds())
+			clock.Advance(tc.advance)
+
+			if got := db.Exists("k"); got != tc.exists {
+				t.Errorf("Expected exists %v, Got %v", tc.exists, got)
+			}
+			if tc.exists {
+				return
+			}
+			if db.Len() != 0 {
+				t.Errorf("Expected the expired key to be deleted, Got %d keys", db.Len())
+			}
+		})
+	}
+}
+
+func TestLockKeys(t *testing.T) {
+	testCases := []struct {
+		name string
+		keys []string
+	}{
+		{"two_keys", []string{"a", "b"}},
+		{"many_keys", []string{"a", "b", "c", "d", "e", "f", "g", "h"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := keyspace.New()
+			for _, key := range tc.keys {
+				db.Set(key, keyspace.String("0"))
+			}
+
+			// Every writer moves a unit between the keys while holding all
+			// of them, so a reader holding them too must always see the
+			// same total.
+			var wg sync.WaitGroup
+			for w := 0; w < 8; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < 200; i++ {
+						unlock := db.Lock(tc.keys...)
+						from, to := tc.keys[(w+i)%len(tc.keys)], tc.keys[(w+i+1)%len(tc.keys)]
+						db.Set(from, keyspace.String(strconv.Itoa(atoi(t, db, from)-1)))
+						db.Set(to, keyspace.String(strconv.Itoa(atoi(t, db, to)+1)))
+						unlock()
+					}
+				}(w)
+			}
+			for i := 0; i < 200; i++ {
+				unlock := db.Lock(tc.keys...)
+				total := 0
+				for _, key := range tc.keys {
+					total += atoi(t, db, key)
+				}
+				unlock()
+				if total != 0 {
+					t.Fatalf("Expected total 0, Got %d", total)
+				}
+			}
+			wg.Wait()
+		})
+	}
+}
+
+// atoi returns the integer stored at key. It is called from several
+// goroutines, so it reports failures without stopping the test.
+func atoi(t *testing.T, db *keyspace.DB, key string) int {
+	s, _, err := db.GetString(key)
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	n, err := strconv.Atoi(string(s))
+	if err != nil {
+		t.Errorf("unexpected value %q", s)
+	}
+	return n
+}
+
+func TestRenameCopy(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(db *keyspace.DB) bool
+		ok     bool
+		src    bool
+		dst    string
+		ttl    bool
+	}{
+		{"rename", func(db *keyspace.DB) bool { return db.Rename("src", "dst") }, true, false, "v", true},
+		{"rename_missing", func(db *keyspace.DB) bool { return db.Rename("missing", "dst") }, false, true, "old", false},
+		{"copy", func(db *keyspace.DB) bool { return db.Copy("src", "dst") }, true, true, "v", true},
+		{"copy_missing", func(db *keyspace.DB) bool { return db.Copy("missing", "dst") }, false, true, "old", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, _ := newTestDB()
+			db.Set("src", keyspace.String("v"))
+			db.SetExpire("src", db.Now()+60000)
+			db.Set("dst", keyspace.String("old"))
+
+			if got := tc.modify(db); got != tc.ok {
+				t.Fatalf("Expected %v, Got %v", tc.ok, got)
+			}
+			if got := db.Exists("src"); got != tc.src {
+				t.Errorf("Expected src exists %v, Got %v", tc.src, got)
+			}
+			if got, _, _ := db.GetString("dst"); string(got) != tc.dst {
+				t.Errorf("Expected dst %q, Got %q", tc.dst, got)
+			}
+			if _, got := db.Expire("dst"); got != tc.ttl {
+				t.Errorf("Expected dst ttl %v, Got %v", tc.ttl, got)
+			}
+		})
+	}
+}
+
+func TestCopyIsDeep(t *testing.T) {
+	db := keyspace.New()
+	db.Set("src", keyspace.String("abc"))
+	db.Copy("src", "dst")
+
+	s, _, _ := db.GetString("dst")
+	s[0] = 'x'
+	if got, _, _ := db.GetString("src"); string(got) != "abc" {
+		t.Errorf("Expected src %q, Got %q", "abc", got)
+	}
+}
+
+func TestRandomKey(t *testing.T) {
+	testCases := []struct {
+		name    string
+		live    []string
+		expired []string
+	}{
+		{"empty", nil, nil},
+		{"only_expired", nil, []string{"a", "b", "c"}},
+		{"skips_expired", []string{"a"}, []string{"b", "c", "d", "e"}},
+		{"many_keys", []string{"a", "b", "c", "d", "e", "f", "g", "h"}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, clock := newTestDB()
+			for _, key := range tc.expired {
+				db.Set(key, keyspace.String("v"))
+				db.SetExpire(key, db.Now()+1)
+			}
+			clock.Advance(time.Second)
+			live := map[string]bool{}
+			for _, key := range tc.live {
+				db.Set(key, keyspace.String("v"))
+				live[key] = true
+			}
+
+			for i := 0; i < 20; i++ {
+				key, ok := db.RandomKey()
+				if ok != (len(tc.live) > 0) || ok && !live[key] {
+					t.Fatalf("Expected one of %q, Got %q %v", tc.live, key, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	db, clock := newTestDB()
+	for i := 0; i < 200; i++ {
+		db.Set(strconv.Itoa(i), keyspace.String("v"))
+	}
+	for i := 200; i < 300; i++ {
+		db.Set(strconv.Itoa(i), keyspace.String("v"))
+		db.SetExpire(strconv.Itoa(i), db.Now()+1)
+	}
+	clock.Advance(time.Second)
+
+	seen := map[string]bool{}
+	var cursor uint64
+	for step := 0; ; step++ {
+		cursor = db.Scan(cursor, func(key string, _ keyspace.Value) { seen[key] = true })
+		if cursor == 0 {
+			break
+		}
+		db.Set("new"+strconv.Itoa(step), keyspace.String("v"))
+	}
+	for i := 0; i < 200; i++ {
+		if !seen[strconv.Itoa(i)] {
+			t.Errorf("Expected key %d to be seen", i)
+		}
+	}
+	for i := 200; i < 300; i++ {
+		if seen[strconv.Itoa(i)] {
+			t.Errorf("Expected expired key %d not to be seen", i)
+		}
+	}
+}
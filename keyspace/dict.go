@@ -0,0 +1,165 @@
+package keyspace
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"math/rand"
+)
+
+// dictMinSize is the smallest number of buckets a Dict shrinks to.
+const dictMinSize = 4
+
+// Dict is a hash table from strings to values. Unlike a map it can be
+// iterated a step at a time with a cursor, as SCAN does, while it changes
+// in between the steps.
+//
+// Like the dict of Redis, it chains entries in a power of two number of
+// buckets, grows when it holds as many entries as buckets and shrinks when
+// fewer than an eighth of the buckets would be used.
+type Dict[V any] struct {
+	table []*dictEntry[V]
+	used  int
+	seed  maphash.Seed
+}
+
+type dictEntry[V any] struct {
+	key   string
+	value V
+	next  *dictEntry[V]
+}
+
+func NewDict[V any]() *Dict[V] {
+	return &Dict[V]{seed: maphash.MakeSeed()}
+}
+
+func (d *Dict[V]) Len() int {
+	return d.used
+}
+
+func (d *Dict[V]) bucket(key string) uint64 {
+	return maphash.String(d.seed, key) & uint64(len(d.table)-1)
+}
+
+func (d *Dict[V]) find(key string) *dictEntry[V] {
+	if d.used == 0 {
+		return nil
+	}
+	for e := d.table[d.bucket(key)]; e != nil; e = e.next {
+		if e.key == key {
+			return e
+		}
+	}
+	return nil
+}
+
+func (d *Dict[V]) Get(key string) (V, bool) {
+	if e := d.find(key); e != nil {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Set stores v at key and reports whether key is new.
+func (d *Dict[V]) Set(key string, v V) bool {
+	if e := d.find(key); e != nil {
+		e.value = v
+		return false
+	}
+	if d.used >= len(d.table) {
+		d.resize(max(2*len(d.table), dictMinSize))
+	}
+	i := d.bucket(key)
+	d.table[i] = &dictEntry[V]{key: key, value: v, next: d.table[i]}
+	d.used++
+	return true
+}
+
+// Delete removes key and reports whether it was there.
+func (d *Dict[V]) Delete(key string) bool {
+	if d.used == 0 {
+		return false
+	}
+	for p := &d.table[d.bucket(key)]; *p != nil; p = &(*p).next {
+		if (*p).key == key {
+			*p = (*p).next
+			d.used--
+			if len(d.table) > dictMinSize && d.used*8 < len(d.table) {
+				d.resize(max(1<<bits.Len(uint(d.used)), dictMinSize))
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Dict[V]) resize(size int) {
+	old := d.table
+	d.table = make([]*dictEntry[V], size)
+	for _, e := range old {
+		for e != nil {
+			next := e.next
+			i := d.bucket(e.key)
+			e.next = d.table[i]
+			d.table[i] = e
+			e = next
+		}
+	}
+}
+
+// Range calls fn for every entry until fn returns false. fn must not
+// modify the Dict.
+func (d *Dict[V]) Range(fn func(key string, v V) bool) {
+	for _, e := range d.table {
+		for ; e != nil; e = e.next {
+			if !fn(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Random returns a random entry. Entries in short chains are a little more
+// likely to be picked than others, which is as fair as Redis gets too.
+func (d *Dict[V]) Random() (string, V, bool) {
+	if d.used == 0 {
+		var zero V
+		return "", zero, false
+	}
+	e := d.table[rand.Intn(len(d.table))]
+	for e == nil {
+		e = d.table[rand.Intn(len(d.table))]
+	}
+	n := 0
+	for c := e; c != nil; c = c.next {
+		n++
+	}
+	for n = rand.Intn(n); n > 0; n-- {
+		e = e.next
+	}
+	return e.key, e.value, true
+}
+
+// Scan calls fn for the entries of one bucket and returns the cursor of the
+// next one, or 0 once every bucket was visited. fn must not modify the
+// Dict, but the Dict may change in between calls to Scan.
+//
+// Starting from cursor 0, every entry that is in the Dict for the whole
+// iteration is visited at least once, and some may be visited more than
+// once. This holds across resizes because the cursor counts with its bits
+// reversed: the buckets an entry can move to when the table doubles or
+// halves share the low bits of its bucket, and so are visited right
+// after each other.
+func (d *Dict[V]) Scan(cursor uint64, fn func(key string, v V)) uint64 {
+	if d.used == 0 {
+		return 0
+	}
+	mask := uint64(len(d.table) - 1)
+	for e := d.table[cursor&mask]; e != nil; e = e.next {
+		fn(e.key, e.value)
+	}
+	cursor |= ^mask
+	cursor = bits.Reverse64(cursor)
+	cursor++
+	return bits.Reverse64(cursor)
+}
@@ -0,0 +1,127 @@
+package keyspace_test
+
+import (
+	"gored/keyspace"
+	"strconv"
+	"testing"
+)
+
+func TestDict(t *testing.T) {
+	d := keyspace.NewDict[int]()
+	for i := 0; i < 1000; i++ {
+		if !d.Set(strconv.Itoa(i), i) {
+			t.Fatalf("Expected %d to be new", i)
+		}
+	}
+	if d.Set("7", -7) {
+		t.Errorf("Expected 7 to exist")
+	}
+	for i := 0; i < 1000; i += 2 {
+		if !d.Delete(strconv.Itoa(i)) {
+			t.Fatalf("Expected %d to be deleted", i)
+		}
+	}
+	if d.Delete("0") {
+		t.Errorf("Expected 0 to be gone")
+	}
+
+	if d.Len() != 500 {
+		t.Errorf("Expected 500 entries, Got %d", d.Len())
+	}
+	for i := 0; i < 1000; i++ {
+		want := i
+		if i == 7 {
+			want = -7
+		}
+		got, ok := d.Get(strconv.Itoa(i))
+		if ok != (i%2 == 1) || ok && got != want {
+			t.Errorf("Expected %d %v, Got %d %v", want, i%2 == 1, got, ok)
+		}
+	}
+}
+
+func TestDictScan(t *testing.T) {
+	testCases := []struct {
+		name   string
+		before int
+		// modify is called in between steps and returns whether there is
+		// more to do.
+		modify func(d *keyspace.Dict[int], step int) bool
+	}{
+		{"unchanged", 100, func(d *keyspace.Dict[int], step int) bool { return false }},
+		{"growing", 100, func(d *keyspace.Dict[int], step int) bool {
+			for i := 0; i < 50; i++ {
+				d.Set("new"+strconv.Itoa(step*50+i), 0)
+			}
+			return step < 40
+		}},
+		{"shrinking", 2000, func(d *keyspace.Dict[int], step int) bool {
+			for i := 0; i < 50; i++ {
+				d.Delete(strconv.Itoa(100 + step*50 + i))
+			}
+			return 100+step*50 < 2000
+		}},
+		{"growing_then_shrinking", 100, func(d *keyspace.Dict[int], step int) bool {
+			if step < 10 {
+				for i := 0; i < 500; i++ {
+					d.Set("new"+strconv.Itoa(step*500+i), 0)
+				}
+				return true
+			}
+			for i := 0; i < 500; i++ {
+				d.Delete("new" + strconv.Itoa((step-10)*500+i))
+			}
+			return step < 19
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := keyspace.NewDict[int]()
+			for i := 0; i < tc.before; i++ {
+				d.Set(strconv.Itoa(i), i)
+			}
+
+			// The first 100 keys stay for the whole iteration, so they must
+			// all be seen.
+			seen := map[string]bool{}
+			var cursor uint64
+			more := true
+			for step := 0; ; step++ {
+				cursor = d.Scan(cursor, func(key string, _ int) { seen[key] = true })
+				if cursor == 0 {
+					break
+				}
+				if more {
+					more = tc.modify(d, step)
+				}
+			}
+			for i := 0; i < 100; i++ {
+				if !seen[strconv.Itoa(i)] {
+					t.Errorf("Expected key %d to be seen", i)
+				}
+			}
+		})
+	}
+}
+
+func TestDictRandom(t *testing.T) {
+	d := keyspace.NewDict[int]()
+	if _, _, ok := d.Random(); ok {
+		t.Fatalf("Expected nothing from an empty dict")
+	}
+	for i := 0; i < 10; i++ {
+		d.Set(strconv.Itoa(i), i)
+	}
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		key, v, ok := d.Random()
+		if !ok || key != strconv.Itoa(v) {
+			t.Fatalf("Expected a stored entry, Got %q %d %v", key, v, ok)
+		}
+		seen[key] = true
+	}
+	if len(seen) != 10 {
+		t.Errorf("Expected every key to come up, Got %d of them", len(seen))
+	}
+}
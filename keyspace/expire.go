@@ -0,0 +1,111 @@
+package keyspace
+
+import "time"
+
+// Expired keys that are never accessed again are found by sampling, as in
+// Redis' activeExpireCycle: each loop looks at a few keys with an expire
+// time and deletes the expired ones, and loops again while the sample shows
+// that many expired keys remain, until the time budget runs out. Hashes with
+// expiring fields are sampled the same way, counting a hash as expired when
+// it had expired fields.
+const (
+	activeExpireKeysPerLoop     = 20
+	activeExpireAcceptableStale = 10 // percent of a sample
+	activeExpireTimeLimit       = 25 * time.Millisecond
+)
+
+// ActiveExpireCycle deletes a share of the expired keys and hash fields,
+// and returns how many keys it deleted. Unlike the other methods it does
+// its own locking, one shard and one sample at a time, so commands are not
+// held up for the whole cycle.
+func (db *DB) ActiveExpireCycle() int {
+	start := time.Now()
+	deleted := 0
+	for i := range db.shards {
+		for _, sample := range []func(int) (int, int, int){db.expireSample, db.expireFieldsSample} {
+			for {
+				sampled, expired, n := sample(i)
+				deleted += n
+				if time.Since(start) > activeExpireTimeLimit {
+					return deleted
+				}
+				if expired*100 <= sampled*activeExpireAcceptableStale {
+					break
+				}
+			}
+		}
+	}
+	return deleted
+}
+
+// expireSample checks up to activeExpireKeysPerLoop keys with an expire
+// time in shard i. Map iteration starts at a random position, which makes
+// the keys checked a random sample.
+func (db *DB) expireSample(i int) (sampled, expired, deleted int) {
+	sh := &db.shards[i]
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	now := db.Now()
+	for key, at := range sh.expires {
+		if sampled == activeExpireKeysPerLoop {
+			break
+		}
+		sampled++
+		if at < now {
+			db.remove(key)
+			expired++
+		}
+	}
+	return sampled, expired, expired
+}
+
+// expireFieldsSample checks up to activeExpireKeysPerLoop hashes with
+// expiring fields in shard i, deleting their expired fields, and the hashes
+// left without fields. It returns how many hashes it checked, how many had
+// expired fields and how many it deleted.
+func (db *DB) expireFieldsSample(i int) (sampled, expired, deleted int) {
+	sh := &db.shards[i]
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	now := db.Now()
+	for key := range sh.volatileHashes {
+		if sampled == activeExpireKeysPerLoop {
+			break
+		}
+		sampled++
+		v, _ := sh.dict.Get(key)
+		h, ok := v.(*Hash)
+		if !ok || !h.volatile() {
+			// The key was replaced, or its fields made persistent.
+			delete(sh.volatileHashes, key)
+			continue
+		}
+		if h.expireFields(now) == 0 {
+			continue
+		}
+		expired++
+		if h.Len() == 0 {
+			db.remove(key)
+			deleted++
+		} else if !h.volatile() {
+			delete(sh.volatileHashes, key)
+		}
+	}
+	return sampled, expired, deleted
+}
+
+// ActiveExpire runs ActiveExpireCycle every interval until stop is closed.
+func (db *DB) ActiveExpire(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			db.ActiveExpireCycle()
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package keyspace_test
+
+import (
+	"gored/keyspace"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestActiveExpireCycle(t *testing.T) {
+	testCases := []struct {
+		name       string
+		volatile   int
+		persistent int
+		advance    time.Duration
+		want       int
+	}{
+		{"nothing_expired", 100, 10, 0, 0},
+		{"all_expired", 1000, 10, time.Minute, 1000},
+		{"no_expire_times", 0, 100, time.Minute, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, clock := newTestDB()
+			for i := 0; i < tc.volatile; i++ {
+				key := "v" + strconv.Itoa(i)
+				db.Set(key, keyspace.String("x"))
+				db.SetExpire(key, db.Now()+1000)
+			}
+			for i := 0; i < tc.persistent; i++ {
+				db.Set("p"+strconv.Itoa(i), keyspace.String("x"))
+			}
+			clock.Advance(tc.advance)
+
+			deleted := 0
+			for i := 0; i < 1000 && deleted < tc.want; i++ {
+				deleted += db.ActiveExpireCycle()
+			}
+
+			if deleted != tc.want {
+				t.Errorf("Expected %d keys deleted, Got %d", tc.want, deleted)
+			}
+			if want := tc.volatile + tc.persistent - tc.want; db.Len() != want {
+				t.Errorf("Expected %d keys left, Got %d", want, db.Len())
+			}
+		})
+	}
+}
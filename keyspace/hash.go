@@ -0,0 +1,157 @@
+package keyspace
+
+import "container/heap"
+
+// Hash maps fields to values, both strings. Fields may have an expire time
+// of their own, like keys; those are also kept in a heap ordered by time,
+// so that finding the expired fields does not mean looking at all of them.
+type Hash struct {
+	fields  *Dict[string]
+	expires map[string]*fieldExpire
+	heap    fieldHeap
+}
+
+type fieldExpire struct {
+	field string
+	at    int64 // unix time in milliseconds
+	index int   // position in the heap
+}
+
+func NewHash() *Hash {
+	return &Hash{fields: NewDict[string](), expires: map[string]*fieldExpire{}}
+}
+
+func (*Hash) Type() Type { return TypeHash }
+
+func (h *Hash) Clone() Value {
+	c := NewHash()
+	h.fields.Range(func(field, v string) bool {
+		c.fields.Set(field, v)
+		return true
+	})
+	for _, e := range h.heap {
+		c.setExpire(e.field, e.at)
+	}
+	return c
+}
+
+func (h *Hash) Len() int {
+	return h.fields.Len()
+}
+
+func (h *Hash) Get(field string) (string, bool) {
+	return h.fields.Get(field)
+}
+
+// Set stores v at field and removes any expire time of field, as HSET
+// does. It reports whether field is new.
+func (h *Hash) Set(field, v string) bool {
+	h.Persist(field)
+	return h.fields.Set(field, v)
+}
+
+// Update stores v at field and keeps its expire time, for commands that
+// modify a value in place. It reports whether field is new.
+func (h *Hash) Update(field, v string) bool {
+	return h.fields.Set(field, v)
+}
+
+// Delete removes field and reports whether it was there.
+func (h *Hash) Delete(field string) bool {
+	h.Persist(field)
+	return h.fields.Delete(field)
+}
+
+// Expire returns the expire time of field, if it has one.
+func (h *Hash) Expire(field string) (int64, bool) {
+	e, ok := h.expires[field]
+	if !ok {
+		return 0, false
+	}
+	return e.at, true
+}
+
+// Persist removes the expire time of field and reports whether it had one.
+func (h *Hash) Persist(field string) bool {
+	e, ok := h.expires[field]
+	if !ok {
+		return false
+	}
+	heap.Remove(&h.heap, e.index)
+	delete(h.expires, field)
+	return true
+}
+
+// Range calls fn for every field until fn returns false. fn must not
+// modify the Hash.
+func (h *Hash) Range(fn func(field, v string) bool) {
+	h.fields.Range(fn)
+}
+
+// Random returns a random field and its value.
+func (h *Hash) Random() (string, string, bool) {
+	return h.fields.Random()
+}
+
+// Scan implements Scanner, with a field and its value for elements.
+func (h *Hash) Scan(cursor uint64, fn func(elem ...string)) uint64 {
+	return h.fields.Scan(cursor, func(field, v string) {
+		fn(field, v)
+	})
+}
+
+// setExpire sets the expire time of field, which must exist. DB.SetFieldExpire
+// is the way in from outside, as the DB has to know of hashes with expiring
+// fields.
+func (h *Hash) setExpire(field string, at int64) {
+	if e, ok := h.expires[field]; ok {
+		e.at = at
+		heap.Fix(&h.heap, e.index)
+		return
+	}
+	e := &fieldExpire{field: field, at: at}
+	h.expires[field] = e
+	heap.Push(&h.heap, e)
+}
+
+// volatile reports whether any field has an expire time.
+func (h *Hash) volatile() bool {
+	return len(h.expires) > 0
+}
+
+// expireFields deletes the fields whose expire time is before now and
+// returns how many it deleted.
+func (h *Hash) expireFields(now int64) int {
+	n := 0
+	for len(h.heap) > 0 && h.heap[0].at < now {
+		h.Delete(h.heap[0].field)
+		n++
+	}
+	return n
+}
+
+// fieldHeap implements heap.Interface, with the earliest expire time first.
+type fieldHeap []*fieldExpire
+
+func (q fieldHeap) Len() int           { return len(q) }
+func (q fieldHeap) Less(i, j int) bool { return q[i].at < q[j].at }
+
+func (q fieldHeap) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *fieldHeap) Push(x any) {
+	e := x.(*fieldExpire)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *fieldHeap) Pop() any {
+	old := *q
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return e
+}
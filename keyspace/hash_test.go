@@ -0,0 +1,108 @@
+package keyspace_test
+
+import (
+	"gored/keyspace"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestHash(t *testing.T) {
+	h := keyspace.NewHash()
+	if !h.Set("a", "1") || !h.Set("b", "2") || h.Set("a", "3") {
+		t.Fatalf("Expected a and b to be new once")
+	}
+	if !h.Delete("b") || h.Delete("b") {
+		t.Fatalf("Expected b to be deleted once")
+	}
+	if v, ok := h.Get("a"); !ok || v != "3" || h.Len() != 1 {
+		t.Errorf("Expected a=3 only, Got %q %v with %d fields", v, ok, h.Len())
+	}
+
+	c := h.Clone().(*keyspace.Hash)
+	c.Set("a", "4")
+	if v, _ := h.Get("a"); v != "3" {
+		t.Errorf("Expected the original unchanged, Got %q", v)
+	}
+
+	var got []string
+	for cursor := h.Scan(0, func(elem ...string) { got = append(got, elem...) }); cursor != 0; {
+		cursor = h.Scan(cursor, func(elem ...string) { got = append(got, elem...) })
+	}
+	if want := []string{"a", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected scan to give %q, Got %q", want, got)
+	}
+}
+
+func TestHashFieldExpire(t *testing.T) {
+	db, clock := newTestDB()
+	h := keyspace.NewHash()
+	h.Set("a", "1")
+	h.Set("b", "2")
+	h.Set("c", "3")
+	db.Set("h", h)
+	db.SetFieldExpire("h", "a", db.Now()+1000)
+	db.SetFieldExpire("h", "b", db.Now()+2000)
+
+	h.Update("a", "4")
+	if _, ok := h.Expire("a"); !ok {
+		t.Errorf("Expected Update to keep the expire time of a")
+	}
+	h.Set("b", "5")
+	if _, ok := h.Expire("b"); ok {
+		t.Errorf("Expected Set to remove the expire time of b")
+	}
+	c := db.Copy("h", "copy")
+	if at, ok := h.Expire("a"); !c || !ok || at != db.Now()+1000 {
+		t.Errorf("Expected a to expire at %d, Got %d %v", db.Now()+1000, at, ok)
+	}
+
+	clock.Advance(1001 * time.Millisecond)
+	got, ok, _ := db.GetHash("h")
+	if !ok || got.Len() != 2 {
+		t.Fatalf("Expected 2 fields left, Got %v", ok)
+	}
+	if _, ok := got.Get("a"); ok {
+		t.Errorf("Expected a to have expired")
+	}
+	if cp, _, _ := db.GetHash("copy"); cp.Len() != 2 {
+		t.Errorf("Expected the copy to expire a too, Got %d fields", cp.Len())
+	}
+
+	db.SetFieldExpire("h", "b", db.Now())
+	db.SetFieldExpire("h", "c", db.Now()-1)
+	if db.Exists("h") {
+		t.Errorf("Expected the hash deleted with its last field")
+	}
+}
+
+func TestHashFieldActiveExpire(t *testing.T) {
+	db, clock := newTestDB()
+	for i := 0; i < 100; i++ {
+		h := keyspace.NewHash()
+		h.Set("a", "1")
+		h.Set("b", "2")
+		key := "h" + strconv.Itoa(i)
+		db.Set(key, h)
+		db.SetFieldExpire(key, "a", db.Now()+1000)
+		if i%2 == 0 {
+			db.SetFieldExpire(key, "b", db.Now()+1000)
+		}
+	}
+	clock.Advance(time.Minute)
+
+	deleted := 0
+	for i := 0; i < 100; i++ {
+		deleted += db.ActiveExpireCycle()
+	}
+	if deleted != 50 || db.Len() != 50 {
+		t.Fatalf("Expected 50 of 100 hashes deleted, Got %d with %d left", deleted, db.Len())
+	}
+	db.Range(func(key string, v keyspace.Value) bool {
+		if h := v.(*keyspace.Hash); h.Len() != 1 {
+			t.Errorf("Expected %s to keep only b, Got %d fields", key, h.Len())
+		}
+		return true
+	})
+}
@@ -0,0 +1,281 @@
+package keyspace
+
+// listNodeSize is the most elements a List keeps in one node.
+const listNodeSize = 128
+
+// List is a list of strings, stored like the quicklist of Redis: a doubly
+// linked list of nodes that each hold a short slice of elements. Pushing
+// and popping at either end are cheap, and the slices keep the memory
+// overhead per element low.
+type List struct {
+	head, tail *listNode
+	len        int
+}
+
+type listNode struct {
+	prev, next *listNode
+	elems      []string
+}
+
+func NewList() *List {
+	return &List{}
+}
+
+func (*List) Type() Type { return TypeList }
+
+func (l *List) Clone() Value {
+	c := NewList()
+	for n := l.head; n != nil; n = n.next {
+		for _, v := range n.elems {
+			c.PushBack(v)
+		}
+	}
+	return c
+}
+
+func (l *List) Len() int {
+	return l.len
+}
+
+func (l *List) PushFront(v string) {
+	if l.head == nil || len(l.head.elems) == listNodeSize {
+		l.insertNode(nil, l.head)
+	}
+	n := l.head
+	n.elems = append(n.elems, "")
+	copy(n.elems[1:], n.elems)
+	n.elems[0] = v
+	l.len++
+}
+
+func (l *List) PushBack(v string) {
+	if l.tail == nil || len(l.tail.elems) == listNodeSize {
+		l.insertNode(l.tail, nil)
+	}
+	l.tail.elems = append(l.tail.elems, v)
+	l.len++
+}
+
+func (l *List) PopFront() (string, bool) {
+	if l.len == 0 {
+		return "", false
+	}
+	v := l.head.elems[0]
+	l.removeAt(l.head, 0)
+	return v, true
+}
+
+func (l *List) PopBack() (string, bool) {
+	if l.len == 0 {
+		return "", false
+	}
+	n := l.tail
+	v := n.elems[len(n.elems)-1]
+	l.removeAt(n, len(n.elems)-1)
+	return v, true
+}
+
+// Index returns the element at index i, counting from the tail if i is
+// negative, as LINDEX does.
+func (l *List) Index(i int) (string, bool) {
+	n, j, ok := l.locate(i)
+	if !ok {
+		return "", false
+	}
+	return n.elems[j], true
+}
+
+// Set replaces the element at index i, which is counted as in Index, and
+// reports whether there is one.
+func (l *List) Set(i int, v string) bool {
+	n, j, ok := l.locate(i)
+	if ok {
+		n.elems[j] = v
+	}
+	return ok
+}
+
+// Range calls fn for the elements from index start to stop, both included,
+// which must satisfy 0 <= start <= stop < Len.
+func (l *List) Range(start, stop int, fn func(v string)) {
+	n, j, _ := l.locate(start)
+	for i := start; i <= stop; i++ {
+		if j == len(n.elems) {
+			n, j = n.next, 0
+		}
+		fn(n.elems[j])
+		j++
+	}
+}
+
+// Each calls fn with the index and value of every element, from the head
+// or from the tail, until fn returns false.
+func (l *List) Each(reverse bool, fn func(i int, v string) bool) {
+	if !reverse {
+		i := 0
+		for n := l.head; n != nil; n = n.next {
+			for _, v := range n.elems {
+				if !fn(i, v) {
+					return
+				}
+				i++
+			}
+		}
+		return
+	}
+	i := l.len - 1
+	for n := l.tail; n != nil; n = n.prev {
+		for j := len(n.elems) - 1; j >= 0; j-- {
+			if !fn(i, n.elems[j]) {
+				return
+			}
+			i--
+		}
+	}
+}
+
+// Remove removes the elements equal to v, as LREM does: the first count
+// of them if count is positive, the last -count if it is negative, and all
+// of them if it is 0. It returns how many it removed.
+func (l *List) Remove(v string, count int) int {
+	removed := 0
+	if count >= 0 {
+		for n := l.head; n != nil && (count == 0 || removed < count); {
+			next := n.next
+			for j := 0; j < len(n.elems) && (count == 0 || removed < count); {
+				if n.elems[j] != v {
+					j++
+					continue
+				}
+				l.removeAt(n, j)
+				removed++
+			}
+			n = next
+		}
+		return removed
+	}
+	for n := l.tail; n != nil && removed < -count; {
+		prev := n.prev
+		for j := len(n.elems) - 1; j >= 0 && removed < -count; j-- {
+			if n.elems[j] == v {
+				l.removeAt(n, j)
+				removed++
+			}
+		}
+		n = prev
+	}
+	return removed
+}
+
+// Trim keeps the elements from index start to stop, both included, and
+// removes the others. An empty range, with start > stop, removes all.
+func (l *List) Trim(start, stop int) {
+	for i := l.len - 1; i > stop && l.len > 0; i-- {
+		l.PopBack()
+	}
+	for i := 0; i < start && l.len > 0; i++ {
+		l.PopFront()
+	}
+}
+
+// Insert inserts v before or after the first element equal to pivot and
+// reports whether there is one.
+func (l *List) Insert(pivot, v string, after bool) bool {
+	for n := l.head; n != nil; n = n.next {
+		for j, e := range n.elems {
+			if e != pivot {
+				continue
+			}
+			if after {
+				j++
+			}
+			l.insertAt(n, j, v)
+			return true
+		}
+	}
+	return false
+}
+
+// locate returns the node holding index i, counted as in Index, and the
+// position of the element in it. It walks from whichever end is closer.
+func (l *List) locate(i int) (*listNode, int, bool) {
+	if i < 0 {
+		i += l.len
+	}
+	if i < 0 || i >= l.len {
+		return nil, 0, false
+	}
+	if i < l.len/2 {
+		n := l.head
+		for i >= len(n.elems) {
+			i -= len(n.elems)
+			n = n.next
+		}
+		return n, i, true
+	}
+	i = l.len - 1 - i
+	n := l.tail
+	for i >= len(n.elems) {
+		i -= len(n.elems)
+		n = n.prev
+	}
+	return n, len(n.elems) - 1 - i, true
+}
+
+// insertAt inserts v at position j of node n, splitting the node in two if
+// it is full.
+func (l *List) insertAt(n *listNode, j int, v string) {
+	if len(n.elems) == listNodeSize {
+		half := listNodeSize / 2
+		m := l.insertNode(n, n.next)
+		m.elems = append(m.elems, n.elems[half:]...)
+		clear(n.elems[half:])
+		n.elems = n.elems[:half]
+		if j > half {
+			n, j = m, j-half
+		}
+	}
+	n.elems = append(n.elems, "")
+	copy(n.elems[j+1:], n.elems[j:])
+	n.elems[j] = v
+	l.len++
+}
+
+// removeAt removes the element at position j of node n, and the node too
+// once it is empty.
+func (l *List) removeAt(n *listNode, j int) {
+	copy(n.elems[j:], n.elems[j+1:])
+	n.elems[len(n.elems)-1] = ""
+	n.elems = n.elems[:len(n.elems)-1]
+	l.len--
+	if len(n.elems) > 0 {
+		return
+	}
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else {
+		l.head = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else {
+		l.tail = n.prev
+	}
+}
+
+// insertNode links a new, empty node in between prev and next, either of
+// which is nil at the ends of the list.
+func (l *List) insertNode(prev, next *listNode) *listNode {
+	n := &listNode{prev: prev, next: next}
+	if prev != nil {
+		prev.next = n
+	} else {
+		l.head = n
+	}
+	if next != nil {
+		next.prev = n
+	} else {
+		l.tail = n
+	}
+	return n
+}
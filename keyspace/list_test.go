@@ -0,0 +1,213 @@
+package keyspace_test
+
+import (
+	"gored/keyspace"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// elems returns the elements of l, read back in both directions.
+func elems(t *testing.T, l *keyspace.List) []string {
+	t.Helper()
+	var fwd, rev []string
+	l.Each(false, func(i int, v string) bool {
+		if i != len(fwd) {
+			t.Fatalf("Expected index %d, Got %d", len(fwd), i)
+		}
+		fwd = append(fwd, v)
+		return true
+	})
+	l.Each(true, func(i int, v string) bool {
+		rev = append([]string{v}, rev...)
+		return true
+	})
+	if !reflect.DeepEqual(fwd, rev) || len(fwd) != l.Len() {
+		t.Fatalf("Expected the same %d elements both ways, Got %q and %q", l.Len(), fwd, rev)
+	}
+	return fwd
+}
+
+func numbers(from, to int) []string {
+	var s []string
+	for i := from; i < to; i++ {
+		s = append(s, strconv.Itoa(i))
+	}
+	return s
+}
+
+func newListOf(vals []string) *keyspace.List {
+	l := keyspace.NewList()
+	for _, v := range vals {
+		l.PushBack(v)
+	}
+	return l
+}
+
+func TestListPushPop(t *testing.T) {
+	l := keyspace.NewList()
+	var want []string
+	// Enough elements to need several nodes at each end.
+	for i := 0; i < 300; i++ {
+		l.PushBack(strconv.Itoa(i))
+		l.PushFront(strconv.Itoa(-i))
+		want = append([]string{strconv.Itoa(-i)}, want...)
+		want = append(want, strconv.Itoa(i))
+	}
+	if got := elems(t, l); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %q, Got %q", want, got)
+	}
+
+	for len(want) > 0 {
+		v, ok := l.PopFront()
+		if !ok || v != want[0] {
+			t.Fatalf("Expected %q, Got %q %v", want[0], v, ok)
+		}
+		want = want[1:]
+		if len(want) == 0 {
+			break
+		}
+		v, ok = l.PopBack()
+		if !ok || v != want[len(want)-1] {
+			t.Fatalf("Expected %q, Got %q %v", want[len(want)-1], v, ok)
+		}
+		want = want[:len(want)-1]
+	}
+	if _, ok := l.PopBack(); ok || l.Len() != 0 {
+		t.Errorf("Expected an empty list, Got %d elements", l.Len())
+	}
+}
+
+func TestListIndex(t *testing.T) {
+	want := numbers(0, 500)
+	l := newListOf(want)
+
+	for _, i := range []int{0, 1, 127, 128, 250, 499, -1, -128, -129, -500} {
+		j := i
+		if j < 0 {
+			j += len(want)
+		}
+		if v, ok := l.Index(i); !ok || v != want[j] {
+			t.Errorf("Expected %q at %d, Got %q %v", want[j], i, v, ok)
+		}
+		if !l.Set(i, "x"+want[j]) {
+			t.Errorf("Expected to set %d", i)
+		}
+		want[j] = "x" + want[j]
+	}
+	for _, i := range []int{500, -501} {
+		if _, ok := l.Index(i); ok {
+			t.Errorf("Expected nothing at %d", i)
+		}
+		if l.Set(i, "x") {
+			t.Errorf("Expected not to set %d", i)
+		}
+	}
+	if got := elems(t, l); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, Got %q", want, got)
+	}
+
+	var got []string
+	l.Range(120, 260, func(v string) { got = append(got, v) })
+	if !reflect.DeepEqual(got, want[120:261]) {
+		t.Errorf("Expected %q, Got %q", want[120:261], got)
+	}
+}
+
+func TestListRemove(t *testing.T) {
+	// Every third element is "x", spread over several nodes.
+	var vals []string
+	for i := 0; i < 300; i++ {
+		if i%3 == 0 {
+			vals = append(vals, "x")
+		} else {
+			vals = append(vals, strconv.Itoa(i))
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		count   int
+		removed int
+		// kept are the indexes of the x elements that remain.
+		kept func(i int) bool
+	}{
+		{"remove_all", 0, 100, func(i int) bool { return false }},
+		{"remove_first", 5, 5, func(i int) bool { return i >= 15 }},
+		{"remove_last", -5, 5, func(i int) bool { return i < 285 }},
+		{"remove_more_than_there", 1000, 100, func(i int) bool { return false }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := newListOf(vals)
+			if got := l.Remove("x", tc.count); got != tc.removed {
+				t.Errorf("Expected %d removed, Got %d", tc.removed, got)
+			}
+			var want []string
+			for i, v := range vals {
+				if v != "x" || tc.kept(i) {
+					want = append(want, v)
+				}
+			}
+			if got := elems(t, l); !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected %q, Got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestListTrim(t *testing.T) {
+	testCases := []struct {
+		name        string
+		start, stop int
+		want        []string
+	}{
+		{"keep_middle", 100, 199, numbers(100, 200)},
+		{"keep_all", 0, 299, numbers(0, 300)},
+		{"keep_one", 150, 150, numbers(150, 151)},
+		{"keep_none", 5, 4, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := newListOf(numbers(0, 300))
+			l.Trim(tc.start, tc.stop)
+			if got := elems(t, l); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %q, Got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestListInsert(t *testing.T) {
+	l := newListOf(numbers(0, 128))
+	want := numbers(0, 128)
+	// Inserting into a full node splits it.
+	for _, pivot := range []string{"0", "64", "127", "100", "100"} {
+		i := 0
+		for want[i] != pivot {
+			i++
+		}
+		if !l.Insert(pivot, "b"+pivot, false) || !l.Insert(pivot, "a"+pivot, true) {
+			t.Fatalf("Expected to find %q", pivot)
+		}
+		want = append(want[:i], append([]string{"b" + pivot, pivot, "a" + pivot}, want[i+1:]...)...)
+	}
+	if l.Insert("missing", "x", false) {
+		t.Errorf("Expected not to find the pivot")
+	}
+	if got := elems(t, l); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, Got %q", want, got)
+	}
+}
+
+func TestListClone(t *testing.T) {
+	l := newListOf(numbers(0, 200))
+	c := l.Clone().(*keyspace.List)
+	c.Set(0, "x")
+	c.PushBack("y")
+	if got := elems(t, l); !reflect.DeepEqual(got, numbers(0, 200)) {
+		t.Errorf("Expected the original unchanged, Got %q", got)
+	}
+}
@@ -0,0 +1,160 @@
+package keyspace
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+)
+
+// setMaxIntsetEntries is the most members a Set keeps as an intset, as
+// set-max-intset-entries is by default in Redis.
+const setMaxIntsetEntries = 512
+
+// Set is an unordered collection of distinct strings. While all members are
+// integers and there are few of them, they are kept like the intset of
+// Redis, a sorted slice of int64, which takes much less memory than a hash
+// table. The first member that does not fit turns the set into a Dict for
+// good.
+type Set struct {
+	ints    []int64 // sorted, used while members is nil
+	members *Dict[struct{}]
+}
+
+func NewSet() *Set {
+	return &Set{}
+}
+
+func (*Set) Type() Type { return TypeSet }
+
+func (s *Set) Clone() Value {
+	if s.members == nil {
+		return &Set{ints: slices.Clone(s.ints)}
+	}
+	c := &Set{members: NewDict[struct{}]()}
+	s.members.Range(func(m string, _ struct{}) bool {
+		c.members.Set(m, struct{}{})
+		return true
+	})
+	return c
+}
+
+func (s *Set) Len() int {
+	if s.members == nil {
+		return len(s.ints)
+	}
+	return s.members.Len()
+}
+
+// Intset reports whether the members are kept as an intset.
+func (s *Set) Intset() bool {
+	return s.members == nil
+}
+
+func (s *Set) Has(m string) bool {
+	if s.members == nil {
+		n, ok := parseSetInt(m)
+		if !ok {
+			return false
+		}
+		_, found := slices.BinarySearch(s.ints, n)
+		return found
+	}
+	_, ok := s.members.Get(m)
+	return ok
+}
+
+// Add adds m and reports whether it is new.
+func (s *Set) Add(m string) bool {
+	if s.members == nil {
+		if n, ok := parseSetInt(m); ok {
+			i, found := slices.BinarySearch(s.ints, n)
+			if found {
+				return false
+			}
+			if len(s.ints) < setMaxIntsetEntries {
+				s.ints = slices.Insert(s.ints, i, n)
+				return true
+			}
+		}
+		s.convert()
+	}
+	return s.members.Set(m, struct{}{})
+}
+
+// Remove removes m and reports whether it was there.
+func (s *Set) Remove(m string) bool {
+	if s.members == nil {
+		n, ok := parseSetInt(m)
+		if !ok {
+			return false
+		}
+		i, found := slices.BinarySearch(s.ints, n)
+		if found {
+			s.ints = slices.Delete(s.ints, i, i+1)
+		}
+		return found
+	}
+	return s.members.Delete(m)
+}
+
+// Range calls fn for every member until fn returns false. fn must not
+// modify the Set.
+func (s *Set) Range(fn func(m string) bool) {
+	if s.members == nil {
+		for _, n := range s.ints {
+			if !fn(strconv.FormatInt(n, 10)) {
+				return
+			}
+		}
+		return
+	}
+	s.members.Range(func(m string, _ struct{}) bool {
+		return fn(m)
+	})
+}
+
+// Random returns a random member.
+func (s *Set) Random() (string, bool) {
+	if s.members == nil {
+		if len(s.ints) == 0 {
+			return "", false
+		}
+		return strconv.FormatInt(s.ints[rand.Intn(len(s.ints))], 10), true
+	}
+	m, _, ok := s.members.Random()
+	return m, ok
+}
+
+// Scan implements Scanner, with a member for elements. An intset is small
+// enough to be reported in one step, as Redis does.
+func (s *Set) Scan(cursor uint64, fn func(elem ...string)) uint64 {
+	if s.members == nil {
+		s.Range(func(m string) bool {
+			fn(m)
+			return true
+		})
+		return 0
+	}
+	return s.members.Scan(cursor, func(m string, _ struct{}) {
+		fn(m)
+	})
+}
+
+// convert moves the members from the intset to a Dict.
+func (s *Set) convert() {
+	s.members = NewDict[struct{}]()
+	for _, n := range s.ints {
+		s.members.Set(strconv.FormatInt(n, 10), struct{}{})
+	}
+	s.ints = nil
+}
+
+// parseSetInt parses m as an intset member, which it only is if formatting
+// the integer gives back m exactly.
+func parseSetInt(m string) (int64, bool) {
+	n, err := strconv.ParseInt(m, 10, 64)
+	if err != nil || strconv.FormatInt(n, 10) != m {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,87 @@
+package keyspace_test
+
+import (
+	"gored/keyspace"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func members(s *keyspace.Set) []string {
+	var got []string
+	s.Range(func(m string) bool {
+		got = append(got, m)
+		return true
+	})
+	slices.Sort(got)
+	return got
+}
+
+func TestSet(t *testing.T) {
+	testCases := []struct {
+		name   string
+		add    []string
+		remove []string
+		want   []string
+		intset bool
+	}{
+		{"integers", []string{"3", "-1", "2", "3"}, []string{"2", "x"}, []string{"-1", "3"}, true},
+		{"not_canonical", []string{"1", "01", "+2"}, nil, []string{"+2", "01", "1"}, false},
+		{"strings", []string{"a", "1", "b"}, []string{"a", "1"}, []string{"b"}, false},
+		{"out_of_range", []string{"1", "9223372036854775808"}, nil, []string{"1", "9223372036854775808"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := keyspace.NewSet()
+			for _, m := range tc.add {
+				s.Add(m)
+			}
+			for _, m := range tc.remove {
+				s.Remove(m)
+			}
+			if got := members(s); !slices.Equal(got, tc.want) {
+				t.Errorf("Expected %q, Got %q", tc.want, got)
+			}
+			if s.Intset() != tc.intset {
+				t.Errorf("Expected intset %v, Got %v", tc.intset, s.Intset())
+			}
+			for _, m := range tc.want {
+				if !s.Has(m) {
+					t.Errorf("Expected %q to be a member", m)
+				}
+			}
+		})
+	}
+}
+
+func TestSetConversion(t *testing.T) {
+	s := keyspace.NewSet()
+	for i := 0; i < 512; i++ {
+		if !s.Add(strconv.Itoa(i)) || s.Add(strconv.Itoa(i)) {
+			t.Fatalf("Expected %d to be new once", i)
+		}
+	}
+	c := s.Clone().(*keyspace.Set)
+	if !s.Intset() {
+		t.Fatalf("Expected 512 integers to stay an intset")
+	}
+	s.Add("512")
+	if s.Intset() || s.Len() != 513 || !s.Has("0") || !s.Has("512") {
+		t.Errorf("Expected a hash table with 513 members, Got intset %v with %d", s.Intset(), s.Len())
+	}
+	if !c.Intset() || c.Len() != 512 {
+		t.Errorf("Expected the clone unchanged, Got %d members", c.Len())
+	}
+
+	var got []string
+	for cursor := s.Scan(0, func(elem ...string) { got = append(got, elem...) }); cursor != 0; {
+		cursor = s.Scan(cursor, func(elem ...string) { got = append(got, elem...) })
+	}
+	if len(got) != 513 {
+		t.Errorf("Expected scan to give 513 members, Got %d", len(got))
+	}
+	if m, ok := s.Random(); !ok || !s.Has(m) {
+		t.Errorf("Expected a random member, Got %q %v", m, ok)
+	}
+}
@@ -0,0 +1,58 @@
+package keyspace
+
+import "errors"
+
+// ErrWrongType is returned when a command expects a different type of value
+// than the one stored at its key.
+var ErrWrongType = errors.New("wrong kind of value")
+
+// Type is the kind of value stored at a key, as reported by TYPE.
+type Type int
+
+const (
+	TypeString Type = iota
+	TypeList
+	TypeHash
+	TypeSet
+	TypeZSet
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeList:
+		return "list"
+	case TypeHash:
+		return "hash"
+	case TypeSet:
+		return "set"
+	case TypeZSet:
+		return "zset"
+	}
+	return "none"
+}
+
+// Value is anything that can be stored at a key.
+type Value interface {
+	Type() Type
+	// Clone returns a deep copy, which shares nothing with the original.
+	Clone() Value
+}
+
+// String is a binary safe string value.
+type String []byte
+
+func (String) Type() Type { return TypeString }
+
+func (s String) Clone() Value {
+	return String(append([]byte(nil), s...))
+}
+
+// Scanner is a Value whose elements HSCAN and SSCAN iterate over.
+// Scan works like Dict.Scan, calling fn with each element as the strings
+// replied for it, such as a field and its value.
+type Scanner interface {
+	Value
+	Scan(cursor uint64, fn func(elem ...string)) uint64
+}
@@ -0,0 +1,324 @@
+package keyspace
+
+import "math/rand"
+
+// zslMaxLevel is the most levels a skiplist node has, which is plenty for
+// 2^64 elements with a quarter of the nodes going up each level.
+const zslMaxLevel = 32
+
+// ZSet is a sorted set: distinct members, each with a score, ordered by
+// score and then bytewise by member. As in Redis, a hash table maps members
+// to scores and a skiplist keeps the order, with the span of every link so
+// that finding the rank of a member, or the member at a rank, takes
+// O(log n).
+type ZSet struct {
+	scores *Dict[float64]
+	header *zslNode
+	level  int
+}
+
+type zslNode struct {
+	member   string
+	score    float64
+	backward *zslNode
+	level    []zslLevel
+}
+
+type zslLevel struct {
+	forward *zslNode
+	span    int // the number of nodes the link skips over, plus one
+}
+
+func NewZSet() *ZSet {
+	return &ZSet{
+		scores: NewDict[float64](),
+		header: &zslNode{level: make([]zslLevel, zslMaxLevel)},
+		level:  1,
+	}
+}
+
+func (*ZSet) Type() Type { return TypeZSet }
+
+func (z *ZSet) Clone() Value {
+	c := NewZSet()
+	for x := z.header.level[0].forward; x != nil; x = x.level[0].forward {
+		c.Add(x.member, x.score)
+	}
+	return c
+}
+
+func (z *ZSet) Len() int {
+	return z.scores.Len()
+}
+
+func (z *ZSet) Score(m string) (float64, bool) {
+	return z.scores.Get(m)
+}
+
+// Add stores m with score, moving it if it was already there with another
+// score, and reports whether m is new.
+func (z *ZSet) Add(m string, score float64) bool {
+	old, ok := z.scores.Get(m)
+	if ok {
+		if old == score {
+			return false
+		}
+		z.delete(m, old)
+	}
+	z.scores.Set(m, score)
+	z.insert(m, score)
+	return !ok
+}
+
+// Remove removes m and reports whether it was there.
+func (z *ZSet) Remove(m string) bool {
+	score, ok := z.scores.Get(m)
+	if !ok {
+		return false
+	}
+	z.scores.Delete(m)
+	z.delete(m, score)
+	return true
+}
+
+// Rank returns the 0-based rank of m, counted from the highest score if
+// reverse is set.
+func (z *ZSet) Rank(m string, reverse bool) (int, bool) {
+	score, ok := z.scores.Get(m)
+	if !ok {
+		return 0, false
+	}
+	_, rank := z.seek(func(n *zslNode) bool {
+		return n.score < score || n.score == score && n.member <= m
+	})
+	return z.fromRank(rank, reverse), true
+}
+
+// Range calls fn for the members from rank start on, counted as in Rank,
+// until there are no more or fn returns false. fn must not modify the
+// ZSet.
+func (z *ZSet) Range(start int, reverse bool, fn func(m string, score float64) bool) {
+	if start < 0 || start >= z.Len() {
+		return
+	}
+	rank := start + 1
+	if reverse {
+		rank = z.Len() - start
+	}
+	x := z.byRank(rank)
+	for x != nil && fn(x.member, x.score) {
+		if reverse {
+			x = x.backward
+		} else {
+			x = x.level[0].forward
+		}
+	}
+}
+
+// ScoreRank returns the rank, counted as in Rank, of the first member with
+// a score in r, which comes last in the order if reverse is set.
+func (z *ZSet) ScoreRank(r ScoreRange, reverse bool) (int, bool) {
+	if reverse {
+		x, rank := z.seek(func(n *zslNode) bool { return r.belowMax(n.score) })
+		if rank == 0 || !r.Contains(x.score) {
+			return 0, false
+		}
+		return z.fromRank(rank, true), true
+	}
+	x, rank := z.seek(func(n *zslNode) bool { return !r.aboveMin(n.score) })
+	x = x.level[0].forward
+	if x == nil || !r.Contains(x.score) {
+		return 0, false
+	}
+	return rank, true
+}
+
+// LexRank is ScoreRank for a range of members, which only makes sense if
+// all members have the same score.
+func (z *ZSet) LexRank(r LexRange, reverse bool) (int, bool) {
+	if reverse {
+		x, rank := z.seek(func(n *zslNode) bool { return r.belowMax(n.member) })
+		if rank == 0 || !r.Contains(x.member) {
+			return 0, false
+		}
+		return z.fromRank(rank, true), true
+	}
+	x, rank := z.seek(func(n *zslNode) bool { return !r.aboveMin(n.member) })
+	x = x.level[0].forward
+	if x == nil || !r.Contains(x.member) {
+		return 0, false
+	}
+	return rank, true
+}
+
+// CountScores returns the number of members with a score in r.
+func (z *ZSet) CountScores(r ScoreRange) int {
+	first, ok := z.ScoreRank(r, false)
+	if !ok {
+		return 0
+	}
+	last, _ := z.ScoreRank(r, true)
+	return z.Len() - last - first
+}
+
+// Scan works like Dict.Scan over the members and their scores. Unlike the
+// other values a ZSet is not a Scanner, as how scores are written is up to
+// the protocol.
+func (z *ZSet) Scan(cursor uint64, fn func(m string, score float64)) uint64 {
+	return z.scores.Scan(cursor, fn)
+}
+
+// seek walks the skiplist as far as before holds, which it must do for a
+// prefix of the nodes, and returns the last node it holds for, or the
+// header, with its 1-based rank.
+func (z *ZSet) seek(before func(n *zslNode) bool) (*zslNode, int) {
+	x, rank := z.header, 0
+	for i := z.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && before(x.level[i].forward) {
+			rank += x.level[i].span
+			x = x.level[i].forward
+		}
+	}
+	return x, rank
+}
+
+// fromRank converts a 1-based rank in the skiplist to a rank as returned by
+// Rank.
+func (z *ZSet) fromRank(rank int, reverse bool) int {
+	if reverse {
+		return z.Len() - rank
+	}
+	return rank - 1
+}
+
+// byRank returns the node with the 1-based rank.
+func (z *ZSet) byRank(rank int) *zslNode {
+	x, traversed := z.header, 0
+	for i := z.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && traversed+x.level[i].span <= rank {
+			traversed += x.level[i].span
+			x = x.level[i].forward
+		}
+		if traversed == rank {
+			return x
+		}
+	}
+	return nil
+}
+
+func (z *ZSet) insert(m string, score float64) {
+	var update [zslMaxLevel]*zslNode
+	var rank [zslMaxLevel]int
+	x := z.header
+	for i := z.level - 1; i >= 0; i-- {
+		if i < z.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for y := x.level[i].forward; y != nil && (y.score < score || y.score == score && y.member < m); y = x.level[i].forward {
+			rank[i] += x.level[i].span
+			x = y
+		}
+		update[i] = x
+	}
+
+	level := randomLevel()
+	if level > z.level {
+		for i := z.level; i < level; i++ {
+			update[i] = z.header
+			update[i].level[i].span = z.Len() - 1 // the new node is counted already
+		}
+		z.level = level
+	}
+	n := &zslNode{member: m, score: score, level: make([]zslLevel, level)}
+	for i := 0; i < level; i++ {
+		n.level[i].forward = update[i].level[i].forward
+		update[i].level[i].forward = n
+		n.level[i].span = update[i].level[i].span - (rank[0] - rank[i])
+		update[i].level[i].span = rank[0] - rank[i] + 1
+	}
+	// The levels above the new node skip over one more node.
+	for i := level; i < z.level; i++ {
+		update[i].level[i].span++
+	}
+
+	if update[0] != z.header {
+		n.backward = update[0]
+	}
+	if n.level[0].forward != nil {
+		n.level[0].forward.backward = n
+	}
+}
+
+func (z *ZSet) delete(m string, score float64) {
+	var update [zslMaxLevel]*zslNode
+	x := z.header
+	for i := z.level - 1; i >= 0; i-- {
+		for y := x.level[i].forward; y != nil && (y.score < score || y.score == score && y.member < m); y = x.level[i].forward {
+			x = y
+		}
+		update[i] = x
+	}
+	n := x.level[0].forward
+
+	for i := 0; i < z.level; i++ {
+		if update[i].level[i].forward == n {
+			update[i].level[i].span += n.level[i].span - 1
+			update[i].level[i].forward = n.level[i].forward
+		} else {
+			update[i].level[i].span--
+		}
+	}
+	if n.level[0].forward != nil {
+		n.level[0].forward.backward = n.backward
+	}
+	for z.level > 1 && z.header.level[z.level-1].forward == nil {
+		z.level--
+	}
+}
+
+// randomLevel returns a level for a new node, each level above the first
+// having a chance of 1/4.
+func randomLevel() int {
+	level := 1
+	for level < zslMaxLevel && rand.Intn(4) == 0 {
+		level++
+	}
+	return level
+}
+
+// ScoreRange is a range of scores, as ZRANGEBYSCORE takes.
+type ScoreRange struct {
+	Min, Max     float64
+	MinEx, MaxEx bool // whether the ends are excluded
+}
+
+func (r ScoreRange) Contains(score float64) bool {
+	return r.aboveMin(score) && r.belowMax(score)
+}
+
+func (r ScoreRange) aboveMin(score float64) bool {
+	return score > r.Min || !r.MinEx && score == r.Min
+}
+
+func (r ScoreRange) belowMax(score float64) bool {
+	return score < r.Max || !r.MaxEx && score == r.Max
+}
+
+// LexRange is a range of members, compared bytewise, as ZRANGEBYLEX takes.
+type LexRange struct {
+	Min, Max     string
+	MinEx, MaxEx bool // whether the ends are excluded
+	NoMin, NoMax bool // whether the range is open ended, for - and +
+}
+
+func (r LexRange) Contains(m string) bool {
+	return r.aboveMin(m) && r.belowMax(m)
+}
+
+func (r LexRange) aboveMin(m string) bool {
+	return r.NoMin || m > r.Min || !r.MinEx && m == r.Min
+}
+
+func (r LexRange) belowMax(m string) bool {
+	return r.NoMax || m < r.Max || !r.MaxEx && m == r.Max
+}
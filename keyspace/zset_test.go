@@ -0,0 +1,175 @@
+package keyspace_test
+
+import (
+	"gored/keyspace"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+type scored struct {
+	member string
+	score  float64
+}
+
+// zsetOf returns a sorted set with members m0, m1, ... scored 0, 10, ...
+func zsetOf(n int) *keyspace.ZSet {
+	z := keyspace.NewZSet()
+	for i := 0; i < n; i++ {
+		z.Add("m"+strconv.Itoa(i), float64(i*10))
+	}
+	return z
+}
+
+func zrange(z *keyspace.ZSet, start int, reverse bool, n int) []scored {
+	var got []scored
+	z.Range(start, reverse, func(m string, score float64) bool {
+		got = append(got, scored{m, score})
+		return len(got) < n
+	})
+	return got
+}
+
+func TestZSetOrder(t *testing.T) {
+	z := keyspace.NewZSet()
+	var want []scored
+	for i := 0; i < 1000; i++ {
+		m := strconv.Itoa(rand.Intn(500))
+		score := float64(rand.Intn(50))
+		if z.Add(m, score) {
+			want = append(want, scored{m, score})
+		} else {
+			for j := range want {
+				if want[j].member == m {
+					want[j].score = score
+				}
+			}
+		}
+		if i%3 == 0 {
+			victim := strconv.Itoa(rand.Intn(500))
+			if z.Remove(victim) {
+				want = slices.DeleteFunc(want, func(s scored) bool { return s.member == victim })
+			}
+		}
+	}
+	slices.SortFunc(want, func(a, b scored) int {
+		if a.score != b.score {
+			if a.score < b.score {
+				return -1
+			}
+			return 1
+		}
+		if a.member < b.member {
+			return -1
+		}
+		if a.member > b.member {
+			return 1
+		}
+		return 0
+	})
+
+	if got := zrange(z, 0, false, z.Len()); !slices.Equal(got, want) || z.Len() != len(want) {
+		t.Fatalf("Expected %d members in order, Got %d", len(want), len(got))
+	}
+	for i, s := range want {
+		if rank, ok := z.Rank(s.member, false); !ok || rank != i {
+			t.Fatalf("Expected %s at rank %d, Got %d", s.member, i, rank)
+		}
+		if rank, _ := z.Rank(s.member, true); rank != len(want)-1-i {
+			t.Fatalf("Expected %s at reverse rank %d, Got %d", s.member, len(want)-1-i, rank)
+		}
+		if got := zrange(z, i, false, 1); got[0] != s {
+			t.Fatalf("Expected %v at rank %d, Got %v", s, i, got[0])
+		}
+		if got := zrange(z, len(want)-1-i, true, 1); got[0] != s {
+			t.Fatalf("Expected %v at reverse rank %d, Got %v", s, len(want)-1-i, got[0])
+		}
+	}
+}
+
+func TestZSetScoreRange(t *testing.T) {
+	z := zsetOf(10)
+	inf := math.Inf(1)
+	testCases := []struct {
+		name        string
+		r           keyspace.ScoreRange
+		first, last int
+		count       int
+	}{
+		{"inclusive", keyspace.ScoreRange{Min: 20, Max: 50}, 2, 4, 4},
+		{"exclusive", keyspace.ScoreRange{Min: 20, Max: 50, MinEx: true, MaxEx: true}, 3, 5, 2},
+		{"between_scores", keyspace.ScoreRange{Min: 15, Max: 25}, 2, 7, 1},
+		{"infinite", keyspace.ScoreRange{Min: -inf, Max: inf}, 0, 0, 10},
+		{"empty", keyspace.ScoreRange{Min: 21, Max: 29}, -1, -1, 0},
+		{"above_all", keyspace.ScoreRange{Min: 100, Max: inf}, -1, -1, 0},
+		{"reversed", keyspace.ScoreRange{Min: 50, Max: 20}, -1, -1, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			first, ok := z.ScoreRank(tc.r, false)
+			if !ok {
+				first = -1
+			}
+			last, ok := z.ScoreRank(tc.r, true)
+			if !ok {
+				last = -1
+			}
+			if first != tc.first || last != tc.last {
+				t.Errorf("Expected ranks %d and %d, Got %d and %d", tc.first, tc.last, first, last)
+			}
+			if n := z.CountScores(tc.r); n != tc.count {
+				t.Errorf("Expected %d members, Got %d", tc.count, n)
+			}
+		})
+	}
+}
+
+func TestZSetLexRange(t *testing.T) {
+	z := keyspace.NewZSet()
+	for _, m := range []string{"a", "b", "c", "d", "e"} {
+		z.Add(m, 0)
+	}
+	testCases := []struct {
+		name        string
+		r           keyspace.LexRange
+		first, last int
+	}{
+		{"inclusive", keyspace.LexRange{Min: "b", Max: "d"}, 1, 1},
+		{"exclusive", keyspace.LexRange{Min: "b", Max: "d", MinEx: true, MaxEx: true}, 2, 2},
+		{"open", keyspace.LexRange{NoMin: true, NoMax: true}, 0, 0},
+		{"prefix", keyspace.LexRange{Min: "bb", NoMax: true}, 2, 0},
+		{"empty", keyspace.LexRange{Min: "bb", Max: "bc"}, -1, -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			first, ok := z.LexRank(tc.r, false)
+			if !ok {
+				first = -1
+			}
+			last, ok := z.LexRank(tc.r, true)
+			if !ok {
+				last = -1
+			}
+			if first != tc.first || last != tc.last {
+				t.Errorf("Expected ranks %d and %d, Got %d and %d", tc.first, tc.last, first, last)
+			}
+		})
+	}
+}
+
+func TestZSetClone(t *testing.T) {
+	z := zsetOf(3)
+	c := z.Clone().(*keyspace.ZSet)
+	c.Add("m0", 100)
+	c.Remove("m1")
+	if got := zrange(z, 0, false, 3); !slices.Equal(got, []scored{{"m0", 0}, {"m1", 10}, {"m2", 20}}) {
+		t.Errorf("Expected the original unchanged, Got %v", got)
+	}
+	if got := zrange(c, 0, false, 3); !slices.Equal(got, []scored{{"m2", 20}, {"m0", 100}}) {
+		t.Errorf("Expected the clone changed, Got %v", got)
+	}
+}
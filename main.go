@@ -3,18 +3,33 @@ package main
 import (
 	"fmt"
 	"net"
+	"os"
+	"time"
 
-	"gored/respser"
+	"gored/keyspace"
 )
 
+// serverVersion is the Redis version whose behavior gored follows. Clients
+// use it from the HELLO reply to decide which features they may rely on.
+const serverVersion = "7.4.0"
+
 func main() {
-	listener, err := net.Listen("tcp", ":6380")
+	cfg, err := parseConfig(os.Args[1:])
+	if err != nil {
+		os.Exit(2)
+	}
+
+	addr := fmt.Sprintf(":%d", cfg.port)
+	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		fmt.Println("Error listening:", err.Error())
 		return
 	}
 	defer listener.Close()
-	fmt.Println("Listening on :6380")
+	fmt.Println("Listening on", addr)
+
+	db := keyspace.New()
+	go db.ActiveExpire(time.Second/time.Duration(cfg.hz), nil)
 
 	for {
 		conn, err := listener.Accept()
@@ -22,103 +37,6 @@ func main() {
 			fmt.Println("Error accepting: ", err.Error())
 			continue
 		}
-		go handleRequest(conn)
-	}
-}
-
-func handleRequest(conn net.Conn) {
-	buffer := make([]byte, 4096)
-	length, err := conn.Read(buffer)
-	if err != nil {
-		fmt.Println("Error reading:", err.Error())
-	}
-	in := string(buffer[:length])
-	fmt.Printf("input is:\n%s\n", in)
-
-	re, err := respser.RespDecode(in)
-
-	if err != nil {
-		fmt.Println("Error decoding:", err)
-	}
-
-	fmt.Println(re.ToString())
-
-	args := []respser.RespEncoder{}
-	if arr, ok := re.(*respser.Array); ok {
-		for _, e := range *arr.Elements {
-			switch v2 := e.(type) {
-			case *respser.SimpleString:
-				args = append(args, v2)
-				fmt.Println("SimpleString:", *v2)
-			case *respser.ErrorString:
-				args = append(args, v2)
-				fmt.Println("ErrorString:", *v2)
-			case *respser.Integer:
-				args = append(args, v2)
-				fmt.Println("Integer:", *v2)
-			case *respser.BulkString:
-				args = append(args, v2)
-			case *respser.Array:
-				fmt.Println("BulkString:", *v2)
-			default:
-				fmt.Println("Unknown type internal")
-			}
-		}
-	}
-
-	response := handleCommand(args)
-	fmt.Printf("response is: \n%s\n", response)
-	_, err = conn.Write([]byte(response))
-	if err != nil {
-		fmt.Println("Error writing:", err.Error())
-	}
-}
-
-func handleCommand(args []respser.RespEncoder) string {
-	if len(args) == 0 {
-		ss := respser.SimpleString{S: "OK"}
-		return ss.RespEncode()
-	}
-	fmt.Println("args are:")
-	for _, a := range args {
-		fmt.Println(a.ToString())
-	}
-
-	if len(args) == 0 {
-		return ""
-	}
-
-	if len(args) == 1 {
-		if bs, ok := args[0].(*respser.BulkString); ok {
-			var command string
-
-			if bs.S == nil {
-				return ""
-			} else {
-				command = *bs.S
-			}
-			switch command {
-			case "PING":
-				ss := respser.SimpleString{S: "PONG"}
-				return ss.RespEncode()
-			}
-		}
-
-	}
-	if len(args) == 2 {
-		if bs, ok := args[0].(*respser.BulkString); ok {
-			var command string
-
-			if bs.S == nil {
-				return ""
-			} else {
-				command = *bs.S
-			}
-			switch command {
-			case "ECHO":
-				return args[1].RespEncode()
-			}
-		}
+		go handleConnection(conn, cfg, db)
 	}
-	return ""
 }
@@ -18,6 +18,11 @@ func RespDecode(s string) (RespEncoder, error) {
 		return decodeBulkString(s)
 	case strings.HasPrefix(s, "*"):
 		return decodeArray(s)
+	case strings.HasPrefix(s, "_"), strings.HasPrefix(s, "#"), strings.HasPrefix(s, ","),
+		strings.HasPrefix(s, "("), strings.HasPrefix(s, "!"), strings.HasPrefix(s, "="),
+		strings.HasPrefix(s, "%"), strings.HasPrefix(s, "~"), strings.HasPrefix(s, ">"),
+		strings.HasPrefix(s, "|"):
+		return decodeResp3(s)
 	default:
 		return nil, invalidTypeError("RespDecode", s)
 	}
@@ -56,7 +61,7 @@ func decodeInteger(s string) (*Integer, error) {
 	if splited[1] != "" {
 		return nil, invalidInputDataError("decodeInteger", s)
 	}
-	n, err := strconv.Atoi(splited[0])
+	n, err := strconv.ParseInt(splited[0], 10, 64)
 	if err != nil {
 		return nil, errors.Join(invalidInputDataError("decodeInteger", s), err)
 	}
@@ -65,24 +70,33 @@ func decodeInteger(s string) (*Integer, error) {
 
 func decodeBulkString(s string) (*BulkString, error) {
 	s = strings.TrimPrefix(s, "$")
-	splited := strings.Split(s, "\r\n")
-	if len(splited) == 2 {
-		if splited[0] != "-1" || splited[1] != "" {
+	header, rest, found := strings.Cut(s, CRLF)
+	if !found {
+		return nil, invalidInputPartsError("decodeBulkString", s)
+	}
+	l, err := strconv.Atoi(header)
+	if err != nil {
+		return nil, errors.Join(invalidInputDataError("decodeBulkString", s), err)
+	}
+	if l == -1 {
+		if rest != "" {
 			return nil, invalidInputPartsError("decodeBulkString", s)
 		}
 		return &BulkString{}, nil
 	}
-	if len(splited) != 3 {
-		return nil, invalidInputPartsError("decodeBulkString", s)
+	if l < 0 {
+		return nil, invalidInputDataError("decodeBulkString", s)
 	}
-	l, err := strconv.Atoi(splited[0])
-	if err != nil {
-		return nil, errors.Join(invalidInputDataError("decodeBulkString", s), err)
+	// The payload is length prefixed and may contain CRLF, so only the
+	// total size tells trailing data apart from a short payload.
+	if len(rest) > l+len(CRLF) {
+		return nil, invalidInputPartsError("decodeBulkString", s)
 	}
-	if l != len(splited[1]) {
+	if len(rest) < l+len(CRLF) || !strings.HasSuffix(rest, CRLF) {
 		return nil, dataMismatchError("decodeBulkString", s)
 	}
-	return &BulkString{S: &splited[1]}, nil
+	payload := rest[:l]
+	return &BulkString{S: &payload}, nil
 }
 
 func decodeArray(s string) (*Array, error) {
@@ -97,12 +111,25 @@ func decodeArray(s string) (*Array, error) {
 	arr, r, err := ExtractArray(s)
 
 	if err != nil {
-		return nil, errors.Join(decodeError("decodeArray", s), err)
+		return nil, errors.Join(err, decodeError("decodeArray", s))
 	}
 
 	if r != "" {
-		return nil, invalidInputDataError("decodeArray", s)
+		return nil, newRespSerError("decodeArray", s, ErrInvalidInputData, len(s)-len(r))
 	}
 
 	return arr, nil
 }
+
+// decodeResp3 decodes the RESP3 types, which have no string-only decoder of
+// their own, by extracting the value and requiring nothing to be left over.
+func decodeResp3(s string) (RespEncoder, error) {
+	re, r, err := ExtractType(s)
+	if err != nil {
+		return nil, errors.Join(err, decodeError("decodeResp3", s))
+	}
+	if r != "" {
+		return nil, newRespSerError("decodeResp3", s, ErrInvalidInputData, len(s)-len(r))
+	}
+	return re, nil
+}
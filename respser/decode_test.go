@@ -3,6 +3,7 @@ package respser_test
 import (
 	"errors"
 	"gored/respser"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -103,6 +104,9 @@ func TestDecodeInteger(t *testing.T) {
 		{"decode_integer_with_leading_zeros", ":00001000\r\n", &respser.Integer{N: 1000}, nil},
 		{"decode_integer_with_negative_value", ":-1000\r\n", &respser.Integer{N: -1000}, nil},
 		{"decode_integer_with_plus_sign", ":+1000\r\n", &respser.Integer{N: 1000}, nil},
+		{"decode_integer_max_int64", ":9223372036854775807\r\n", &respser.Integer{N: math.MaxInt64}, nil},
+		{"decode_integer_min_int64", ":-9223372036854775808\r\n", &respser.Integer{N: math.MinInt64}, nil},
+		{"decode_integer_overflow", ":9223372036854775808\r\n", nil, respser.ErrInvalidInputData},
 		{"decode_invalid_parts", ":4\r\n3\r\n", nil, respser.ErrInvalidInputParts},
 		{"decode_invalid_data_after_crlf", ":4\r\n3", nil, respser.ErrInvalidInputData},
 		{"decode_string_instead_of_integer", ":foo\r\n", nil, respser.ErrInvalidInputData},
@@ -138,6 +142,10 @@ func TestRespDecodeBulkString(t *testing.T) {
 		{"decode_error_invalid_bulk_string_parts", "$foo\r\nbar\r\n", nil, respser.ErrInvalidInputData},
 		{"decode_error_invalid_bulk_string_length", "$10\r\nfoo\r\n", nil, respser.ErrDataMismatch},
 		{"decode_error_invalid_bulk_string_null", "$0\r\nfoo\r\nsalam\r\n", nil, respser.ErrInvalidInputParts},
+		{"decode_bulk_string_with_crlf_in_string", "$8\r\nfoo\r\nbar\r\n", &respser.BulkString{S: ptr("foo\r\nbar")}, nil},
+		{"decode_bulk_string_with_only_crlf", "$2\r\n\r\n\r\n", &respser.BulkString{S: ptr("\r\n")}, nil},
+		{"decode_error_bulk_string_missing_terminator", "$3\r\nfoobar", nil, respser.ErrInvalidInputParts},
+		{"decode_error_bulk_string_short_payload", "$8\r\nfoo\r\nb\r\n", nil, respser.ErrDataMismatch},
 	}
 
 	for _, tc := range testCases {
@@ -238,6 +246,15 @@ func TestDecodeArray(t *testing.T) {
 					&respser.BulkString{S: ptr("qux")},
 				},
 			}, nil},
+		{
+			"decode_array_with_crlf_in_item",
+			"*2\r\n$8\r\nfoo\r\nbar\r\n$3\r\nqux\r\n",
+			&respser.Array{
+				Elements: &[]respser.RespEncoder{
+					&respser.BulkString{S: ptr("foo\r\nbar")},
+					&respser.BulkString{S: ptr("qux")},
+				},
+			}, nil},
 		{
 			"decode_error_invalid_array_parts",
 			"*foo\r\nbar\r\n",
@@ -271,3 +288,43 @@ func TestDecodeArray(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeResp3Types(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  respser.RespEncoder
+		err   error
+	}{
+		{"decode_null", "_\r\n", &respser.Null{}, nil},
+		{"decode_boolean", "#t\r\n", &respser.Boolean{B: true}, nil},
+		{"decode_double", ",3.14\r\n", &respser.Double{F: 3.14}, nil},
+		{"decode_big_number", "(-12345678901234567890\r\n", &respser.BigNumber{N: bigInt("-12345678901234567890")}, nil},
+		{"decode_bulk_error", "!3\r\nERR\r\n", &respser.BulkError{E: "ERR"}, nil},
+		{"decode_verbatim_string", "=9\r\nmkd:hello\r\n", &respser.VerbatimString{Format: "mkd", S: "hello"}, nil},
+		{
+			"decode_map",
+			"%1\r\n$3\r\nkey\r\n~1\r\n:1\r\n",
+			&respser.Map{Entries: []respser.MapEntry{
+				{Key: &respser.BulkString{S: ptr("key")}, Value: &respser.Set{Elements: []respser.RespEncoder{&respser.Integer{N: 1}}}},
+			}},
+			nil,
+		},
+		{"decode_push", ">1\r\n+hi\r\n", &respser.Push{Elements: []respser.RespEncoder{&respser.SimpleString{S: "hi"}}}, nil},
+		{"decode_error_trailing_data", "#t\r\n#f\r\n", nil, respser.ErrInvalidInputData},
+		{"decode_error_invalid_double", ",abc\r\n", nil, respser.ErrDecode},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := respser.RespDecode(tc.input)
+			if err == nil && !reflect.DeepEqual(s, tc.want) {
+				t.Errorf("Expected %v, Got %v", tc.want, s)
+			}
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Expected error %v, Got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -2,34 +2,92 @@ package respser
 
 import (
 	"errors"
+	"math/big"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 func ExtractType(s string) (RespEncoder, string, error) {
+	return newExtractor(DefaultLimits, s).extractType(s)
+}
+
+// extractor holds the state shared by the extraction of one value: the
+// limits in force, the whole input and the element indexes leading to the
+// current position. Every string it works on is a suffix of src, which is
+// how errors find their offset.
+type extractor struct {
+	limits Limits
+	src    string
+	base   int // offset of src within the stream it was read from
+	path   []int
+}
+
+func newExtractor(limits Limits, src string) *extractor {
+	return &extractor{limits: limits, src: src}
+}
+
+// fail reports err at byte at of s.
+func (x *extractor) fail(err error, fn string, s string, at int) *RespSerError {
+	pos := len(x.src) - len(s) + at
+	return &RespSerError{
+		Func:    fn,
+		In:      s,
+		Err:     err,
+		Offset:  x.base + pos,
+		Path:    slices.Clone(x.path),
+		Excerpt: excerpt(x.src, pos),
+	}
+}
+
+func (x *extractor) extractType(s string) (RespEncoder, string, error) {
 	switch {
 	case strings.HasPrefix(s, "+"):
-		return ExtractSimpleString(s)
+		return x.extractSimpleString(s)
 	case strings.HasPrefix(s, "-"):
-		return ExtractErrorString(s)
+		return x.extractErrorString(s)
 	case strings.HasPrefix(s, ":"):
-		return ExtractInteger(s)
+		return x.extractInteger(s)
 	case strings.HasPrefix(s, "$"):
-		return ExtractBulkString(s)
+		return x.extractBulkString(s)
 	case strings.HasPrefix(s, "*"):
-		return ExtractArray(s)
+		return x.extractArray(s)
+	case strings.HasPrefix(s, "_"):
+		return x.extractNull(s)
+	case strings.HasPrefix(s, "#"):
+		return x.extractBoolean(s)
+	case strings.HasPrefix(s, ","):
+		return x.extractDouble(s)
+	case strings.HasPrefix(s, "("):
+		return x.extractBigNumber(s)
+	case strings.HasPrefix(s, "!"):
+		return x.extractBulkError(s)
+	case strings.HasPrefix(s, "="):
+		return x.extractVerbatimString(s)
+	case strings.HasPrefix(s, "%"):
+		return x.extractMap(s)
+	case strings.HasPrefix(s, "~"):
+		return x.extractSet(s)
+	case strings.HasPrefix(s, ">"):
+		return x.extractPush(s)
+	case strings.HasPrefix(s, "|"):
+		return x.extractAttribute(s)
 	default:
-		return nil, s, invalidTypeError("extractType", s)
+		return nil, s, x.fail(ErrInvalidType, "extractType", s, 0)
 	}
 }
 
 func ExtractSimpleString(s string) (*SimpleString, string, error) {
+	return newExtractor(DefaultLimits, s).extractSimpleString(s)
+}
+
+func (x *extractor) extractSimpleString(s string) (*SimpleString, string, error) {
 	if !strings.HasPrefix(s, "+") {
-		return nil, s, invalidTypeError("extractSimpleString", s)
+		return nil, s, x.fail(ErrInvalidType, "extractSimpleString", s, 0)
 	}
 	splited := strings.SplitN(s, CRLF, 2)
 	if len(splited) != 2 {
-		return nil, s, invalidInputPartsError("extractSimpleString", s)
+		return nil, s, x.fail(ErrInvalidInputParts, "extractSimpleString", s, len(s))
 	}
 	internalString := strings.TrimPrefix(splited[0], "+")
 	ss := &SimpleString{
@@ -39,12 +97,16 @@ func ExtractSimpleString(s string) (*SimpleString, string, error) {
 }
 
 func ExtractErrorString(s string) (*ErrorString, string, error) {
+	return newExtractor(DefaultLimits, s).extractErrorString(s)
+}
+
+func (x *extractor) extractErrorString(s string) (*ErrorString, string, error) {
 	if !strings.HasPrefix(s, "-") {
-		return nil, s, invalidTypeError("extractErrorString", s)
+		return nil, s, x.fail(ErrInvalidType, "extractErrorString", s, 0)
 	}
 	splited := strings.SplitN(s, CRLF, 2)
 	if len(splited) != 2 {
-		return nil, s, invalidInputPartsError("extractErrorString", s)
+		return nil, s, x.fail(ErrInvalidInputParts, "extractErrorString", s, len(s))
 	}
 	internalString := strings.TrimPrefix(splited[0], "-")
 	ss := &ErrorString{
@@ -54,17 +116,21 @@ func ExtractErrorString(s string) (*ErrorString, string, error) {
 }
 
 func ExtractInteger(s string) (*Integer, string, error) {
+	return newExtractor(DefaultLimits, s).extractInteger(s)
+}
+
+func (x *extractor) extractInteger(s string) (*Integer, string, error) {
 	if !strings.HasPrefix(s, ":") {
-		return nil, s, invalidTypeError("extractInteger", s)
+		return nil, s, x.fail(ErrInvalidType, "extractInteger", s, 0)
 	}
 	splited := strings.SplitN(s, CRLF, 2)
 	if len(splited) != 2 {
-		return nil, s, invalidInputPartsError("extractInteger", s)
+		return nil, s, x.fail(ErrInvalidInputParts, "extractInteger", s, len(s))
 	}
 	internalIntString := strings.TrimPrefix(splited[0], ":")
-	internalInteger, err := strconv.Atoi(internalIntString)
+	internalInteger, err := strconv.ParseInt(internalIntString, 10, 64)
 	if err != nil {
-		return nil, s, invalidInputDataError("extractInteger", s)
+		return nil, s, x.fail(ErrInvalidInputData, "extractInteger", s, 1)
 	}
 	ss := &Integer{
 		N: internalInteger,
@@ -72,60 +138,57 @@ func ExtractInteger(s string) (*Integer, string, error) {
 	return ss, splited[1], nil
 }
 
+// ExtractBulkString reads exactly the declared number of bytes, so the
+// payload may itself contain CRLF or any other binary data.
 func ExtractBulkString(s string) (*BulkString, string, error) {
+	return newExtractor(DefaultLimits, s).extractBulkString(s)
+}
+
+func (x *extractor) extractBulkString(s string) (*BulkString, string, error) {
 	if !strings.HasPrefix(s, "$") {
-		return nil, s, invalidTypeError("extractBulkString", s)
+		return nil, s, x.fail(ErrInvalidType, "extractBulkString", s, 0)
 	}
-	splited := strings.SplitN(s, CRLF, 3)
-	if len(splited) == 2 {
-		if splited[0] != "$-1" || splited[1] != "" {
-			return nil, s, invalidInputPartsError("decodeBulkString", s)
-		}
-		return &BulkString{}, splited[1], nil
+	if rest, ok := strings.CutPrefix(s, "$-1"+CRLF); ok {
+		return &BulkString{S: nil}, rest, nil
 	}
-	if len(splited) != 3 {
-		return nil, s, invalidInputPartsError("extractBulkString", s)
-	}
-	internalBulkStringSizeString := strings.TrimPrefix(splited[0], "$")
-	internalBulkStringSize, err := strconv.Atoi(internalBulkStringSizeString)
+	internalString, rest, err := x.extractBlob("extractBulkString", s)
 	if err != nil {
-		return nil, s, invalidInputDataError("extractBulkString", s)
-	}
-	if internalBulkStringSize == -1 {
-		return &BulkString{S: nil}, splited[1] + CRLF + splited[2], nil
-	}
-
-	internalString := splited[1]
-	if len(internalString) != internalBulkStringSize {
-		return nil, s, dataMismatchError("extractBulkString", s)
+		return nil, s, err
 	}
 	ss := &BulkString{
 		S: &internalString,
 	}
-	return ss, splited[2], nil
+	return ss, rest, nil
 }
 
 func ExtractArray(s string) (*Array, string, error) {
+	return newExtractor(DefaultLimits, s).extractArray(s)
+}
+
+func (x *extractor) extractArray(s string) (*Array, string, error) {
 	if !strings.HasPrefix(s, "*") {
-		return nil, s, invalidTypeError("extractArray", s)
+		return nil, s, x.fail(ErrInvalidType, "extractArray", s, 0)
 	}
 	splited := strings.SplitN(s, CRLF, 2)
 	if len(splited) != 2 {
-		return nil, s, invalidInputPartsError("extractArray", s)
+		return nil, s, x.fail(ErrInvalidInputParts, "extractArray", s, len(s))
 	}
 	internalSizeString := strings.TrimPrefix(splited[0], "*")
 	internalSize, err := strconv.Atoi(internalSizeString)
 	if err != nil {
-		return nil, s, invalidInputDataError("extractArray", s)
+		return nil, s, x.fail(ErrInvalidInputData, "extractArray", s, 1)
+	}
+	if err := x.checkAggregate("extractArray", s, internalSize); err != nil {
+		return nil, s, err
 	}
 
 	a := &Array{}
 	remainder := splited[1]
 
 	for i := 0; i < internalSize; i++ {
-		re, r, err := ExtractType(remainder)
+		re, r, err := x.extractChild(i, remainder)
 		if err != nil {
-			return nil, s, errors.Join(failedExtractionError("extractArray", s), err)
+			return nil, s, errors.Join(err, x.fail(ErrFailedExtraction, "extractArray", s, 0))
 		}
 		a.AddElement(re)
 		remainder = r
@@ -133,3 +196,270 @@ func ExtractArray(s string) (*Array, string, error) {
 
 	return a, remainder, nil
 }
+
+func ExtractNull(s string) (*Null, string, error) {
+	return newExtractor(DefaultLimits, s).extractNull(s)
+}
+
+func (x *extractor) extractNull(s string) (*Null, string, error) {
+	line, rest, err := x.extractLine("extractNull", "_", s)
+	if err != nil {
+		return nil, s, err
+	}
+	if line != "" {
+		return nil, s, x.fail(ErrInvalidInputData, "extractNull", s, 1)
+	}
+	return &Null{}, rest, nil
+}
+
+func ExtractBoolean(s string) (*Boolean, string, error) {
+	return newExtractor(DefaultLimits, s).extractBoolean(s)
+}
+
+func (x *extractor) extractBoolean(s string) (*Boolean, string, error) {
+	line, rest, err := x.extractLine("extractBoolean", "#", s)
+	if err != nil {
+		return nil, s, err
+	}
+	switch line {
+	case "t":
+		return &Boolean{B: true}, rest, nil
+	case "f":
+		return &Boolean{B: false}, rest, nil
+	default:
+		return nil, s, x.fail(ErrInvalidInputData, "extractBoolean", s, 1)
+	}
+}
+
+func ExtractDouble(s string) (*Double, string, error) {
+	return newExtractor(DefaultLimits, s).extractDouble(s)
+}
+
+func (x *extractor) extractDouble(s string) (*Double, string, error) {
+	line, rest, err := x.extractLine("extractDouble", ",", s)
+	if err != nil {
+		return nil, s, err
+	}
+	f, err := strconv.ParseFloat(line, 64)
+	if err != nil {
+		return nil, s, x.fail(ErrInvalidInputData, "extractDouble", s, 1)
+	}
+	return &Double{F: f}, rest, nil
+}
+
+func ExtractBigNumber(s string) (*BigNumber, string, error) {
+	return newExtractor(DefaultLimits, s).extractBigNumber(s)
+}
+
+func (x *extractor) extractBigNumber(s string) (*BigNumber, string, error) {
+	line, rest, err := x.extractLine("extractBigNumber", "(", s)
+	if err != nil {
+		return nil, s, err
+	}
+	n, ok := new(big.Int).SetString(line, 10)
+	if !ok {
+		return nil, s, x.fail(ErrInvalidInputData, "extractBigNumber", s, 1)
+	}
+	return &BigNumber{N: n}, rest, nil
+}
+
+func ExtractBulkError(s string) (*BulkError, string, error) {
+	return newExtractor(DefaultLimits, s).extractBulkError(s)
+}
+
+func (x *extractor) extractBulkError(s string) (*BulkError, string, error) {
+	if !strings.HasPrefix(s, "!") {
+		return nil, s, x.fail(ErrInvalidType, "extractBulkError", s, 0)
+	}
+	e, rest, err := x.extractBlob("extractBulkError", s)
+	if err != nil {
+		return nil, s, err
+	}
+	return &BulkError{E: e}, rest, nil
+}
+
+func ExtractVerbatimString(s string) (*VerbatimString, string, error) {
+	return newExtractor(DefaultLimits, s).extractVerbatimString(s)
+}
+
+func (x *extractor) extractVerbatimString(s string) (*VerbatimString, string, error) {
+	if !strings.HasPrefix(s, "=") {
+		return nil, s, x.fail(ErrInvalidType, "extractVerbatimString", s, 0)
+	}
+	payload, rest, err := x.extractBlob("extractVerbatimString", s)
+	if err != nil {
+		return nil, s, err
+	}
+	if len(payload) < 4 || payload[3] != ':' {
+		return nil, s, x.fail(ErrInvalidInputData, "extractVerbatimString", s, strings.Index(s, CRLF)+len(CRLF))
+	}
+	return &VerbatimString{Format: payload[:3], S: payload[4:]}, rest, nil
+}
+
+func ExtractMap(s string) (*Map, string, error) {
+	return newExtractor(DefaultLimits, s).extractMap(s)
+}
+
+func (x *extractor) extractMap(s string) (*Map, string, error) {
+	if !strings.HasPrefix(s, "%") {
+		return nil, s, x.fail(ErrInvalidType, "extractMap", s, 0)
+	}
+	entries, rest, err := x.extractEntries("extractMap", s)
+	if err != nil {
+		return nil, s, err
+	}
+	return &Map{Entries: entries}, rest, nil
+}
+
+func ExtractSet(s string) (*Set, string, error) {
+	return newExtractor(DefaultLimits, s).extractSet(s)
+}
+
+func (x *extractor) extractSet(s string) (*Set, string, error) {
+	if !strings.HasPrefix(s, "~") {
+		return nil, s, x.fail(ErrInvalidType, "extractSet", s, 0)
+	}
+	elements, rest, err := x.extractElements("extractSet", s)
+	if err != nil {
+		return nil, s, err
+	}
+	return &Set{Elements: elements}, rest, nil
+}
+
+func ExtractPush(s string) (*Push, string, error) {
+	return newExtractor(DefaultLimits, s).extractPush(s)
+}
+
+func (x *extractor) extractPush(s string) (*Push, string, error) {
+	if !strings.HasPrefix(s, ">") {
+		return nil, s, x.fail(ErrInvalidType, "extractPush", s, 0)
+	}
+	elements, rest, err := x.extractElements("extractPush", s)
+	if err != nil {
+		return nil, s, err
+	}
+	return &Push{Elements: elements}, rest, nil
+}
+
+func ExtractAttribute(s string) (*Attribute, string, error) {
+	return newExtractor(DefaultLimits, s).extractAttribute(s)
+}
+
+func (x *extractor) extractAttribute(s string) (*Attribute, string, error) {
+	if !strings.HasPrefix(s, "|") {
+		return nil, s, x.fail(ErrInvalidType, "extractAttribute", s, 0)
+	}
+	entries, rest, err := x.extractEntries("extractAttribute", s)
+	if err != nil {
+		return nil, s, err
+	}
+	return &Attribute{Entries: entries}, rest, nil
+}
+
+// extractLine returns the text between the type prefix and the first CRLF.
+func (x *extractor) extractLine(fn string, prefix string, s string) (string, string, error) {
+	if !strings.HasPrefix(s, prefix) {
+		return "", s, x.fail(ErrInvalidType, fn, s, 0)
+	}
+	line, rest, found := strings.Cut(s[len(prefix):], CRLF)
+	if !found {
+		return "", s, x.fail(ErrInvalidInputParts, fn, s, len(s))
+	}
+	return line, rest, nil
+}
+
+// extractBlob reads a length prefixed payload such as "$5\r\nhello\r\n".
+// The type prefix has already been checked by the caller.
+func (x *extractor) extractBlob(fn string, s string) (string, string, error) {
+	header, rest, found := strings.Cut(s, CRLF)
+	if !found {
+		return "", s, x.fail(ErrInvalidInputParts, fn, s, len(s))
+	}
+	size, err := strconv.Atoi(header[1:])
+	if err != nil || size < 0 {
+		return "", s, x.fail(ErrInvalidInputData, fn, s, 1)
+	}
+	if x.limits.MaxBulkLen > 0 && size > x.limits.MaxBulkLen {
+		return "", s, x.fail(ErrBulkLengthExceeded, fn, s, 1)
+	}
+	end := size + len(CRLF)
+	if len(rest) < end || rest[size:end] != CRLF {
+		return "", s, x.fail(ErrDataMismatch, fn, s, len(header)+len(CRLF)+min(size, len(rest)))
+	}
+	return rest[:size], rest[end:], nil
+}
+
+// checkAggregate enforces the limits on an aggregate of n elements that is
+// about to be entered at the current depth.
+func (x *extractor) checkAggregate(fn string, s string, n int) error {
+	if x.limits.MaxMultibulkLen > 0 && n > x.limits.MaxMultibulkLen {
+		return x.fail(ErrMultibulkLengthExceeded, fn, s, 1)
+	}
+	if x.limits.MaxDepth > 0 && len(x.path) >= x.limits.MaxDepth {
+		return x.fail(ErrNestingTooDeep, fn, s, 0)
+	}
+	return nil
+}
+
+// extractCount parses the element count of an aggregate header such as "*3\r\n".
+func (x *extractor) extractCount(fn string, s string) (int, string, error) {
+	header, rest, found := strings.Cut(s, CRLF)
+	if !found {
+		return 0, s, x.fail(ErrInvalidInputParts, fn, s, len(s))
+	}
+	n, err := strconv.Atoi(header[1:])
+	if err != nil || n < 0 {
+		return 0, s, x.fail(ErrInvalidInputData, fn, s, 1)
+	}
+	if err := x.checkAggregate(fn, s, n); err != nil {
+		return 0, s, err
+	}
+	return n, rest, nil
+}
+
+// extractChild extracts the i-th element of the aggregate being read.
+func (x *extractor) extractChild(i int, s string) (RespEncoder, string, error) {
+	x.path = append(x.path, i)
+	defer func() { x.path = x.path[:len(x.path)-1] }()
+	return x.extractType(s)
+}
+
+func (x *extractor) extractElements(fn string, s string) ([]RespEncoder, string, error) {
+	n, remainder, err := x.extractCount(fn, s)
+	if err != nil {
+		return nil, s, err
+	}
+	elements := []RespEncoder{}
+	for i := 0; i < n; i++ {
+		re, r, err := x.extractChild(i, remainder)
+		if err != nil {
+			return nil, s, errors.Join(err, x.fail(ErrFailedExtraction, fn, s, 0))
+		}
+		elements = append(elements, re)
+		remainder = r
+	}
+	return elements, remainder, nil
+}
+
+// extractEntries reads the key/value pairs of a map. In error paths keys
+// and values are numbered as one flat sequence, as they appear on the wire.
+func (x *extractor) extractEntries(fn string, s string) ([]MapEntry, string, error) {
+	n, remainder, err := x.extractCount(fn, s)
+	if err != nil {
+		return nil, s, err
+	}
+	entries := []MapEntry{}
+	for i := 0; i < n; i++ {
+		key, r, err := x.extractChild(2*i, remainder)
+		if err != nil {
+			return nil, s, errors.Join(err, x.fail(ErrFailedExtraction, fn, s, 0))
+		}
+		value, r, err := x.extractChild(2*i+1, r)
+		if err != nil {
+			return nil, s, errors.Join(err, x.fail(ErrFailedExtraction, fn, s, 0))
+		}
+		entries = append(entries, MapEntry{Key: key, Value: value})
+		remainder = r
+	}
+	return entries, remainder, nil
+}
@@ -3,6 +3,7 @@ package respser_test
 import (
 	"errors"
 	"gored/respser"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -130,6 +131,11 @@ func TestExtractBulkString(t *testing.T) {
 		{"extract_invalid_input_parts_bulk_string", "$6", nil, "$6", respser.ErrInvalidInputParts},
 		{"extract_invalid_input_type_bulk_string", "$-1.5\r\nhi\r\n", nil, "$-1.5\r\nhi\r\n", respser.ErrInvalidInputData},
 		{"extract_data_mismatch_bulk_string", "$6\r\nfoo\r\n", nil, "$6\r\nfoo\r\n", respser.ErrDataMismatch},
+		{"extract_bulk_string_with_crlf_in_string", "$8\r\nfoo\r\nbar\r\n:1\r\n", &respser.BulkString{S: ptr("foo\r\nbar")}, ":1\r\n", nil},
+		{"extract_bulk_string_with_binary_data", "$4\r\n\x00\xff\r\n\r\n", &respser.BulkString{S: ptr("\x00\xff\r\n")}, "", nil},
+		{"extract_null_bulk_string_with_extra", "$-1\r\n+OK\r\n", &respser.BulkString{}, "+OK\r\n", nil},
+		{"extract_bulk_string_missing_terminator", "$3\r\nfoobar\r\n", nil, "$3\r\nfoobar\r\n", respser.ErrDataMismatch},
+		{"extract_invalid_negative_length_bulk_string", "$-2\r\n", nil, "$-2\r\n", respser.ErrInvalidInputData},
 	}
 
 	for _, tc := range testCases {
@@ -187,3 +193,83 @@ func TestExtractArray(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractResp3Types(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  respser.RespEncoder
+		want1 string
+		err   error
+	}{
+		{"extract_null", "_\r\n", &respser.Null{}, "", nil},
+		{"extract_null_with_remainder", "_\r\n:1\r\n", &respser.Null{}, ":1\r\n", nil},
+		{"extract_invalid_null", "_x\r\n", nil, "_x\r\n", respser.ErrInvalidInputData},
+		{"extract_true", "#t\r\n", &respser.Boolean{B: true}, "", nil},
+		{"extract_false", "#f\r\n", &respser.Boolean{B: false}, "", nil},
+		{"extract_invalid_boolean", "#yes\r\n", nil, "#yes\r\n", respser.ErrInvalidInputData},
+		{"extract_invalid_parts_boolean", "#t", nil, "#t", respser.ErrInvalidInputParts},
+		{"extract_double", ",1.23\r\n", &respser.Double{F: 1.23}, "", nil},
+		{"extract_double_with_exponent", ",1.5e3\r\n", &respser.Double{F: 1500}, "", nil},
+		{"extract_double_infinity", ",-inf\r\n", &respser.Double{F: math.Inf(-1)}, "", nil},
+		{"extract_invalid_double", ",one\r\n", nil, ",one\r\n", respser.ErrInvalidInputData},
+		{"extract_big_number", "(3492890328409238509324850943850943825024385\r\n", &respser.BigNumber{N: bigInt("3492890328409238509324850943850943825024385")}, "", nil},
+		{"extract_invalid_big_number", "(12a\r\n", nil, "(12a\r\n", respser.ErrInvalidInputData},
+		{"extract_bulk_error", "!21\r\nSYNTAX invalid syntax\r\n", &respser.BulkError{E: "SYNTAX invalid syntax"}, "", nil},
+		{"extract_bulk_error_data_mismatch", "!5\r\nERR\r\n", nil, "!5\r\nERR\r\n", respser.ErrDataMismatch},
+		{"extract_verbatim_string", "=15\r\ntxt:Some string\r\n", &respser.VerbatimString{Format: "txt", S: "Some string"}, "", nil},
+		{"extract_invalid_verbatim_string_format", "=5\r\ntext!\r\n", nil, "=5\r\ntext!\r\n", respser.ErrInvalidInputData},
+		{
+			"extract_map",
+			"%2\r\n+first\r\n:1\r\n+second\r\n:2\r\n",
+			&respser.Map{Entries: []respser.MapEntry{
+				{Key: &respser.SimpleString{S: "first"}, Value: &respser.Integer{N: 1}},
+				{Key: &respser.SimpleString{S: "second"}, Value: &respser.Integer{N: 2}},
+			}},
+			"",
+			nil,
+		},
+		{"extract_empty_map", "%0\r\n", &respser.Map{Entries: []respser.MapEntry{}}, "", nil},
+		{"extract_map_missing_value", "%1\r\n+key\r\n", nil, "%1\r\n+key\r\n", respser.ErrFailedExtraction},
+		{
+			"extract_set",
+			"~2\r\n$1\r\na\r\n#f\r\n+rest\r\n",
+			&respser.Set{Elements: []respser.RespEncoder{&respser.BulkString{S: ptr("a")}, &respser.Boolean{B: false}}},
+			"+rest\r\n",
+			nil,
+		},
+		{
+			"extract_push",
+			">2\r\n$7\r\nmessage\r\n:1\r\n",
+			&respser.Push{Elements: []respser.RespEncoder{&respser.BulkString{S: ptr("message")}, &respser.Integer{N: 1}}},
+			"",
+			nil,
+		},
+		{
+			"extract_attribute_leaves_reply",
+			"|1\r\n+ttl\r\n:3600\r\n$2\r\nhi\r\n",
+			&respser.Attribute{Entries: []respser.MapEntry{{Key: &respser.SimpleString{S: "ttl"}, Value: &respser.Integer{N: 3600}}}},
+			"$2\r\nhi\r\n",
+			nil,
+		},
+		{"extract_invalid_aggregate_length", "~-1\r\n", nil, "~-1\r\n", respser.ErrInvalidInputData},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, got1, got2 := respser.ExtractType(tc.input)
+
+			if got2 == nil && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, Got %v", tc.want, got)
+			}
+
+			if got1 != tc.want1 {
+				t.Errorf("Expected remainder %v, Got %v", tc.want1, got1)
+			}
+
+			if !errors.Is(got2, tc.err) {
+				t.Errorf("Expected error %v, Got %v", tc.err, got2)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package respser
+
+import (
+	"strings"
+)
+
+// SplitArgs splits an inline command line into arguments the way redis-cli
+// and the Redis server do. Arguments are separated by whitespace and may be
+// quoted: double quotes understand the escapes \n, \r, \t, \b, \a, \\, \"
+// and \xHH, single quotes only understand \'. A closing quote must be
+// followed by whitespace or the end of the line.
+func SplitArgs(line string) ([]string, error) {
+	args := []string{}
+	i := 0
+	for {
+		for i < len(line) && isSpace(line[i]) {
+			i++
+		}
+		if i >= len(line) {
+			return args, nil
+		}
+
+		var arg strings.Builder
+		inDouble, inSingle := false, false
+		for done := false; !done; i++ {
+			switch {
+			case inDouble:
+				switch {
+				case i == len(line):
+					return nil, newRespSerError("SplitArgs", line, ErrUnbalancedQuotes, i)
+				case line[i] == '\\' && i+3 < len(line) && line[i+1] == 'x' && isHex(line[i+2]) && isHex(line[i+3]):
+					arg.WriteByte(unhex(line[i+2])<<4 | unhex(line[i+3]))
+					i += 3
+				case line[i] == '\\' && i+1 < len(line):
+					i++
+					arg.WriteByte(unescape(line[i]))
+				case line[i] == '"':
+					if i+1 < len(line) && !isSpace(line[i+1]) {
+						return nil, newRespSerError("SplitArgs", line, ErrUnbalancedQuotes, i+1)
+					}
+					done = true
+				default:
+					arg.WriteByte(line[i])
+				}
+			case inSingle:
+				switch {
+				case i == len(line):
+					return nil, newRespSerError("SplitArgs", line, ErrUnbalancedQuotes, i)
+				case line[i] == '\\' && i+1 < len(line) && line[i+1] == '\'':
+					i++
+					arg.WriteByte('\'')
+				case line[i] == '\'':
+					if i+1 < len(line) && !isSpace(line[i+1]) {
+						return nil, newRespSerError("SplitArgs", line, ErrUnbalancedQuotes, i+1)
+					}
+					done = true
+				default:
+					arg.WriteByte(line[i])
+				}
+			default:
+				switch {
+				case i == len(line) || isSpace(line[i]):
+					done = true
+				case line[i] == '"':
+					inDouble = true
+				case line[i] == '\'':
+					inSingle = true
+				default:
+					arg.WriteByte(line[i])
+				}
+			}
+		}
+		args = append(args, arg.String())
+	}
+}
+
+func isSpace(b byte) bool {
+	switch b {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+	return false
+}
+
+func isHex(b byte) bool {
+	return '0' <= b && b <= '9' || 'a' <= b && b <= 'f' || 'A' <= b && b <= 'F'
+}
+
+func unhex(b byte) byte {
+	switch {
+	case '0' <= b && b <= '9':
+		return b - '0'
+	case 'a' <= b && b <= 'f':
+		return b - 'a' + 10
+	default:
+		return b - 'A' + 10
+	}
+}
+
+func unescape(b byte) byte {
+	switch b {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'b':
+		return '\b'
+	case 'a':
+		return '\a'
+	default:
+		return b
+	}
+}
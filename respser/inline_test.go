@@ -0,0 +1,47 @@
+package respser_test
+
+import (
+	"errors"
+	"gored/respser"
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  []string
+		err   error
+	}{
+		{"split_single_word", "PING", []string{"PING"}, nil},
+		{"split_words", "SET key value", []string{"SET", "key", "value"}, nil},
+		{"split_extra_whitespace", "  SET\tkey   value  ", []string{"SET", "key", "value"}, nil},
+		{"split_empty_line", "", []string{}, nil},
+		{"split_blank_line", "   \t", []string{}, nil},
+		{"split_double_quoted", `SET key "hello world"`, []string{"SET", "key", "hello world"}, nil},
+		{"split_empty_double_quoted", `SET key ""`, []string{"SET", "key", ""}, nil},
+		{"split_double_quoted_escapes", `ECHO "a\nb\tc\\d\"e"`, []string{"ECHO", "a\nb\tc\\d\"e"}, nil},
+		{"split_hex_escape", `ECHO "\x00\xff\x41"`, []string{"ECHO", "\x00\xffA"}, nil},
+		{"split_invalid_hex_escape", `ECHO "\xzz"`, []string{"ECHO", "xzz"}, nil},
+		{"split_single_quoted", `ECHO 'it\'s "raw" \n'`, []string{"ECHO", `it's "raw" \n`}, nil},
+		{"split_quote_inside_word", `ECHO foo"bar baz"`, []string{"ECHO", "foobar baz"}, nil},
+		{"split_unbalanced_double_quote", `ECHO "hello`, nil, respser.ErrUnbalancedQuotes},
+		{"split_unbalanced_single_quote", `ECHO 'hello`, nil, respser.ErrUnbalancedQuotes},
+		{"split_text_after_closing_quote", `ECHO "hello"world`, nil, respser.ErrUnbalancedQuotes},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := respser.SplitArgs(tc.input)
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %q, Got %q", tc.want, got)
+			}
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Expected error %v, Got %v", tc.err, err)
+			}
+		})
+	}
+}
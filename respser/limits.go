@@ -0,0 +1,30 @@
+package respser
+
+// Limits bounds the resources a single value can make the decoder use, so a
+// hostile peer cannot exhaust memory or stack with huge declared lengths or
+// deeply nested aggregates. A zero field means no limit.
+type Limits struct {
+	// MaxBulkLen is the largest bulk string, bulk error or verbatim string
+	// payload in bytes, like Redis' proto-max-bulk-len.
+	MaxBulkLen int
+	// MaxMultibulkLen is the largest number of elements (or map entries)
+	// one aggregate may declare.
+	MaxMultibulkLen int
+	// MaxDepth is how deeply aggregates may be nested; 1 allows aggregates
+	// of scalars only.
+	MaxDepth int
+	// MaxInlineLen is the longest line the Reader accepts, whether an
+	// inline command or the header of a RESP value.
+	MaxInlineLen int
+}
+
+// DefaultLimits are the limits used by the Extract functions, RespDecode
+// and new Readers. They match the Redis defaults where Redis has one; for
+// the number of elements that is the limit Redis puts on clients that have
+// not authenticated, as any peer can send a header such as *2147483647.
+var DefaultLimits = Limits{
+	MaxBulkLen:      512 * 1024 * 1024,
+	MaxMultibulkLen: 1024 * 1024,
+	MaxDepth:        32,
+	MaxInlineLen:    64 * 1024,
+}
@@ -0,0 +1,117 @@
+package respser_test
+
+import (
+	"errors"
+	"gored/respser"
+	"strings"
+	"testing"
+)
+
+func TestExtractTypeDefaultLimits(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{"extract_huge_bulk_string", "$2147483647\r\nfoo\r\n", respser.ErrBulkLengthExceeded},
+		{"extract_huge_bulk_error", "!1000000000000\r\nfoo\r\n", respser.ErrBulkLengthExceeded},
+		{"extract_huge_array", "*2147483648\r\n:1\r\n", respser.ErrMultibulkLengthExceeded},
+		{"extract_max_int32_array", "*2147483647\r\n:1\r\n", respser.ErrMultibulkLengthExceeded},
+		{"extract_array_over_default", "*1048577\r\n:1\r\n", respser.ErrMultibulkLengthExceeded},
+		{"extract_huge_map", "%2147483648\r\n", respser.ErrMultibulkLengthExceeded},
+		{"extract_deeply_nested_array", strings.Repeat("*1\r\n", 33) + ":1\r\n", respser.ErrNestingTooDeep},
+		{"extract_deeply_nested_set", strings.Repeat("~1\r\n", 33) + ":1\r\n", respser.ErrNestingTooDeep},
+		{"extract_nested_array_at_limit", strings.Repeat("*1\r\n", 32) + ":1\r\n", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := respser.ExtractType(tc.input)
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Expected error %v, Got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestReaderLimits(t *testing.T) {
+	limits := respser.Limits{MaxBulkLen: 8, MaxMultibulkLen: 3, MaxDepth: 2, MaxInlineLen: 16}
+
+	testCases := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{"read_bulk_string_within_limit", "$8\r\n12345678\r\n", nil},
+		{"read_bulk_string_over_limit", "$9\r\n123456789\r\n", respser.ErrBulkLengthExceeded},
+		{"read_bulk_string_declared_huge", "$2147483647\r\n", respser.ErrBulkLengthExceeded},
+		{"read_array_within_limit", "*3\r\n:1\r\n:2\r\n:3\r\n", nil},
+		{"read_array_over_limit", "*4\r\n:1\r\n:2\r\n:3\r\n:4\r\n", respser.ErrMultibulkLengthExceeded},
+		{"read_array_declared_huge", "*2147483647\r\n", respser.ErrMultibulkLengthExceeded},
+		{"read_map_over_limit", "%4\r\n", respser.ErrMultibulkLengthExceeded},
+		{"read_nesting_within_limit", "*1\r\n*1\r\n:1\r\n", nil},
+		{"read_nesting_over_limit", "*1\r\n*1\r\n*1\r\n:1\r\n", respser.ErrNestingTooDeep},
+		{"read_line_over_limit", "+" + strings.Repeat("a", 20) + "\r\n", respser.ErrInlineTooLong},
+		{"read_unterminated_line_over_limit", strings.Repeat("+", 100), respser.ErrInlineTooLong},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := respser.NewReader(strings.NewReader(tc.input))
+			r.SetLimits(limits)
+			_, err := r.ReadValue()
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Expected error %v, Got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestReaderInlineLimit(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{"read_inline_within_limit", "SET k v\r\n", nil},
+		{"read_inline_over_limit", "SET key " + strings.Repeat("v", 20) + "\r\n", respser.ErrInlineTooLong},
+		{"read_inline_without_newline_over_limit", strings.Repeat("x", 4096*3), respser.ErrInlineTooLong},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := respser.NewReader(strings.NewReader(tc.input))
+			r.SetLimits(respser.Limits{MaxInlineLen: 16})
+			_, err := r.ReadCommand()
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Expected error %v, Got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestReaderDefaultLimits(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{"read_max_int32_array", "*2147483647\r\n", respser.ErrMultibulkLengthExceeded},
+		{"read_array_over_default", "*1048577\r\n", respser.ErrMultibulkLengthExceeded},
+		{"read_array_within_default", "*2\r\n$4\r\nECHO\r\n$1\r\na\r\n", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := respser.NewReader(strings.NewReader(tc.input))
+			_, err := r.ReadCommand()
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Expected error %v, Got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package respser
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var respEncoderType = reflect.TypeOf((*RespEncoder)(nil)).Elem()
+
+// Marshal converts a Go value into a RESP value:
+//
+//   - RespEncoder values are returned unchanged
+//   - strings and []byte become bulk strings
+//   - signed and unsigned integers become integers, or big numbers for
+//     unsigned values that do not fit in 64 signed bits
+//   - floats become doubles and bools become booleans
+//   - slices and arrays become arrays; a nil slice is an empty array
+//   - maps and structs become RESP3 maps, which ToProtocol flattens for RESP2
+//   - nil pointers and interfaces become Null
+//
+// Struct fields are keyed by their name, or by the name given in a
+// `resp:"name"` tag. A tag of "-" skips the field and the "omitempty" option
+// skips it when it holds its zero value. Map entries are ordered by their
+// encoded key so the result is deterministic.
+func Marshal(v any) (RespEncoder, error) {
+	return marshalValue(reflect.ValueOf(v))
+}
+
+func marshalValue(rv reflect.Value) (RespEncoder, error) {
+	if !rv.IsValid() || (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) && rv.IsNil() {
+		return &Null{}, nil
+	}
+	if re, ok := rv.Interface().(RespEncoder); ok {
+		return re, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return marshalValue(rv.Elem())
+	case reflect.String:
+		return NewBulkString(rv.String()), nil
+	case reflect.Bool:
+		return &Boolean{B: rv.Bool()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Integer{N: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := rv.Uint()
+		if n > math.MaxInt64 {
+			return &BigNumber{N: new(big.Int).SetUint64(n)}, nil
+		}
+		return &Integer{N: int64(n)}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Double{F: rv.Float()}, nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return NewBulkBytes(rv.Bytes()), nil
+		}
+		return marshalElements(rv)
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return NewBulkBytes(b), nil
+		}
+		return marshalElements(rv)
+	case reflect.Map:
+		return marshalMap(rv)
+	case reflect.Struct:
+		return marshalStruct(rv)
+	default:
+		return nil, unsupportedTypeError("Marshal", rv.Type().String())
+	}
+}
+
+func marshalElements(rv reflect.Value) (RespEncoder, error) {
+	elements := make([]RespEncoder, rv.Len())
+	for i := range elements {
+		e, err := marshalValue(rv.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = e
+	}
+	return &Array{Elements: &elements}, nil
+}
+
+func marshalMap(rv reflect.Value) (RespEncoder, error) {
+	m := &Map{Entries: make([]MapEntry, 0, rv.Len())}
+	iter := rv.MapRange()
+	for iter.Next() {
+		k, err := marshalValue(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		v, err := marshalValue(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		m.AddEntry(k, v)
+	}
+	sort.Slice(m.Entries, func(i, j int) bool {
+		return bytes.Compare(m.Entries[i].Key.AppendResp(nil), m.Entries[j].Key.AppendResp(nil)) < 0
+	})
+	return m, nil
+}
+
+func marshalStruct(rv reflect.Value) (RespEncoder, error) {
+	m := &Map{}
+	for _, f := range structFields(rv.Type()) {
+		fv := rv.Field(f.index)
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+		v, err := marshalValue(fv)
+		if err != nil {
+			return nil, err
+		}
+		m.AddEntry(NewBulkString(f.name), v)
+	}
+	return m, nil
+}
+
+type structField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+func structFields(t reflect.Type) []structField {
+	fields := []structField{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("resp")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, structField{name: name, index: i, omitEmpty: opts == "omitempty"})
+	}
+	return fields
+}
+
+// Unmarshal stores the RESP value re in the value pointed to by v. It
+// accepts the conversions made by Marshal and the ones needed to read real
+// replies: numbers and booleans may arrive as bulk strings, and maps and
+// structs may be filled from a flat RESP2 key/value array. Null values set
+// the target to its zero value. Struct keys are matched against the field
+// name or tag, falling back to a case-insensitive match; unknown keys are
+// ignored. An error reply is returned as an error wrapping ErrErrorReply.
+func Unmarshal(re RespEncoder, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return invalidTargetError("Unmarshal", fmt.Sprintf("%T", v))
+	}
+	return unmarshalValue(re, rv.Elem())
+}
+
+func unmarshalValue(re RespEncoder, rv reflect.Value) error {
+	switch e := re.(type) {
+	case *ErrorString:
+		return errorReplyError("Unmarshal", e.E)
+	case *BulkError:
+		return errorReplyError("Unmarshal", e.E)
+	}
+
+	if rv.Type() == respEncoderType {
+		rv.Set(reflect.ValueOf(re))
+		return nil
+	}
+	if isNull(re) {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return unmarshalValue(re, rv.Elem())
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return unsupportedTypeError("Unmarshal", rv.Type().String())
+		}
+		if g := goValue(re); g != nil {
+			rv.Set(reflect.ValueOf(g))
+		}
+		return nil
+	case reflect.String:
+		if s, ok := scalarText(re); ok {
+			rv.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		switch e := re.(type) {
+		case *Boolean:
+			rv.SetBool(e.B)
+			return nil
+		case *Integer:
+			rv.SetBool(e.N != 0)
+			return nil
+		}
+		if s, ok := scalarText(re); ok {
+			if b, err := strconv.ParseBool(s); err == nil {
+				rv.SetBool(b)
+				return nil
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s, ok := scalarText(re); ok {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err == nil && !rv.OverflowInt(n) {
+				rv.SetInt(n)
+				return nil
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if s, ok := scalarText(re); ok {
+			n, err := strconv.ParseUint(s, 10, 64)
+			if err == nil && !rv.OverflowUint(n) {
+				rv.SetUint(n)
+				return nil
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		if d, ok := re.(*Double); ok {
+			rv.SetFloat(d.F)
+			return nil
+		}
+		if s, ok := scalarText(re); ok {
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				rv.SetFloat(f)
+				return nil
+			}
+		}
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			if s, ok := scalarText(re); ok {
+				rv.SetBytes([]byte(s))
+				return nil
+			}
+			break
+		}
+		if elements, ok := aggregateElements(re); ok {
+			s := reflect.MakeSlice(rv.Type(), len(elements), len(elements))
+			for i, e := range elements {
+				if err := unmarshalValue(e, s.Index(i)); err != nil {
+					return err
+				}
+			}
+			rv.Set(s)
+			return nil
+		}
+	case reflect.Array:
+		if elements, ok := aggregateElements(re); ok {
+			for i := 0; i < rv.Len(); i++ {
+				if i >= len(elements) {
+					rv.Index(i).Set(reflect.Zero(rv.Type().Elem()))
+					continue
+				}
+				if err := unmarshalValue(elements[i], rv.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if entries, ok := aggregateEntries(re); ok {
+			m := reflect.MakeMapWithSize(rv.Type(), len(entries))
+			for _, e := range entries {
+				k := reflect.New(rv.Type().Key()).Elem()
+				if err := unmarshalValue(e.Key, k); err != nil {
+					return err
+				}
+				v := reflect.New(rv.Type().Elem()).Elem()
+				if err := unmarshalValue(e.Value, v); err != nil {
+					return err
+				}
+				m.SetMapIndex(k, v)
+			}
+			rv.Set(m)
+			return nil
+		}
+	case reflect.Struct:
+		if entries, ok := aggregateEntries(re); ok {
+			fields := structFields(rv.Type())
+			for _, e := range entries {
+				name, _ := scalarText(e.Key)
+				f, ok := findField(fields, name)
+				if !ok {
+					continue
+				}
+				if err := unmarshalValue(e.Value, rv.Field(f.index)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	return unsupportedTypeError("Unmarshal", re.ToString()+" into "+rv.Type().String())
+}
+
+func findField(fields []structField, name string) (structField, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
+
+func isNull(re RespEncoder) bool {
+	switch v := re.(type) {
+	case *Null:
+		return true
+	case *BulkString:
+		return v.S == nil
+	case *Array:
+		return v.Elements == nil
+	}
+	return false
+}
+
+// scalarText returns the textual form of a non-aggregate value.
+func scalarText(re RespEncoder) (string, bool) {
+	switch v := re.(type) {
+	case *BulkString:
+		if v.S != nil {
+			return *v.S, true
+		}
+	case *SimpleString:
+		return v.S, true
+	case *VerbatimString:
+		return v.S, true
+	case *Integer:
+		return strconv.FormatInt(v.N, 10), true
+	case *Double:
+		return FormatDouble(v.F), true
+	case *BigNumber:
+		return v.digits(), true
+	case *Boolean:
+		if v.B {
+			return "1", true
+		}
+		return "0", true
+	}
+	return "", false
+}
+
+func aggregateElements(re RespEncoder) ([]RespEncoder, bool) {
+	switch v := re.(type) {
+	case *Array:
+		return v.GetElements(), true
+	case *Set:
+		return v.Elements, true
+	case *Push:
+		return v.Elements, true
+	}
+	return nil, false
+}
+
+// aggregateEntries returns the key/value pairs of a map, or of an array
+// holding a map flattened for RESP2.
+func aggregateEntries(re RespEncoder) ([]MapEntry, bool) {
+	switch v := re.(type) {
+	case *Map:
+		return v.Entries, true
+	case *Attribute:
+		return v.Entries, true
+	}
+	elements, ok := aggregateElements(re)
+	if !ok || len(elements)%2 != 0 {
+		return nil, false
+	}
+	entries := make([]MapEntry, len(elements)/2)
+	for i := range entries {
+		entries[i] = MapEntry{Key: elements[2*i], Value: elements[2*i+1]}
+	}
+	return entries, true
+}
+
+// goValue converts re into the natural Go value used for interface targets.
+func goValue(re RespEncoder) any {
+	switch v := re.(type) {
+	case *Integer:
+		return v.N
+	case *Double:
+		return v.F
+	case *Boolean:
+		return v.B
+	case *BigNumber:
+		return v.N
+	case *Array, *Set, *Push:
+		elements, _ := aggregateElements(v)
+		res := make([]any, len(elements))
+		for i, e := range elements {
+			res[i] = goValue(e)
+		}
+		return res
+	case *Map, *Attribute:
+		entries, _ := aggregateEntries(v)
+		res := make(map[string]any, len(entries))
+		for _, e := range entries {
+			k, ok := scalarText(e.Key)
+			if !ok {
+				k = e.Key.ToString()
+			}
+			res[k] = goValue(e.Value)
+		}
+		return res
+	}
+	if s, ok := scalarText(re); ok {
+		return s
+	}
+	return nil
+}
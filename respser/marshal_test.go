@@ -0,0 +1,164 @@
+package respser_test
+
+import (
+	"errors"
+	"gored/respser"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type session struct {
+	User    string            `resp:"user"`
+	Visits  int               `resp:"visits"`
+	Score   float64           `resp:"score,omitempty"`
+	Admin   bool              `resp:"admin"`
+	Tags    []string          `resp:"tags"`
+	Secret  string            `resp:"-"`
+	Extra   map[string]string `resp:"extra,omitempty"`
+	private int
+}
+
+func TestMarshal(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input any
+		want  string
+		err   error
+	}{
+		{"marshal_string", "hello", "$5\r\nhello\r\n", nil},
+		{"marshal_bytes", []byte("a\r\nb"), "$4\r\na\r\nb\r\n", nil},
+		{"marshal_int", 42, ":42\r\n", nil},
+		{"marshal_negative_int8", int8(-3), ":-3\r\n", nil},
+		{"marshal_int64_min", int64(math.MinInt64), ":-9223372036854775808\r\n", nil},
+		{"marshal_uint_too_large", uint64(math.MaxUint64), "(18446744073709551615\r\n", nil},
+		{"marshal_float", 1.5, ",1.5\r\n", nil},
+		{"marshal_bool", true, "#t\r\n", nil},
+		{"marshal_nil", nil, "_\r\n", nil},
+		{"marshal_nil_pointer", (*string)(nil), "_\r\n", nil},
+		{"marshal_pointer", ptr("x"), "$1\r\nx\r\n", nil},
+		{"marshal_slice", []int{1, 2}, "*2\r\n:1\r\n:2\r\n", nil},
+		{"marshal_nil_slice", []string(nil), "*0\r\n", nil},
+		{"marshal_array", [2]string{"a", "b"}, "*2\r\n$1\r\na\r\n$1\r\nb\r\n", nil},
+		{"marshal_map_sorted", map[string]int{"b": 2, "a": 1}, "%2\r\n$1\r\na\r\n:1\r\n$1\r\nb\r\n:2\r\n", nil},
+		{"marshal_any_slice", []any{"a", 1, nil}, "*3\r\n$1\r\na\r\n:1\r\n_\r\n", nil},
+		{"marshal_resp_encoder", &respser.SimpleString{S: "OK"}, "+OK\r\n", nil},
+		{"marshal_resp_encoder_in_slice", []respser.RespEncoder{&respser.Integer{N: 1}}, "*1\r\n:1\r\n", nil},
+		{
+			"marshal_struct",
+			session{User: "ann", Visits: 3, Admin: true, Tags: []string{"x"}, Secret: "s"},
+			"%4\r\n$4\r\nuser\r\n$3\r\nann\r\n$6\r\nvisits\r\n:3\r\n$5\r\nadmin\r\n#t\r\n$4\r\ntags\r\n*1\r\n$1\r\nx\r\n",
+			nil,
+		},
+		{"marshal_struct_without_tags", struct{ A, B int }{1, 2}, "%2\r\n$1\r\nA\r\n:1\r\n$1\r\nB\r\n:2\r\n", nil},
+		{"marshal_unsupported_channel", make(chan int), "", respser.ErrUnsupportedType},
+		{"marshal_unsupported_nested_func", []any{func() {}}, "", respser.ErrUnsupportedType},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := respser.Marshal(tc.input)
+
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Expected error %v, Got %v", tc.err, err)
+			}
+			if err == nil && got.RespEncode() != tc.want {
+				t.Errorf("Expected %q, Got %q", tc.want, got.RespEncode())
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	decode := func(s string) respser.RespEncoder {
+		re, err := respser.RespDecode(s)
+		if err != nil {
+			t.Fatalf("invalid test input %q: %v", s, err)
+		}
+		return re
+	}
+
+	testCases := []struct {
+		name   string
+		input  respser.RespEncoder
+		target any
+		want   any
+		err    error
+	}{
+		{"unmarshal_bulk_string", decode("$5\r\nhello\r\n"), new(string), "hello", nil},
+		{"unmarshal_simple_string", decode("+OK\r\n"), new(string), "OK", nil},
+		{"unmarshal_bulk_into_bytes", decode("$3\r\nabc\r\n"), new([]byte), []byte("abc"), nil},
+		{"unmarshal_integer", decode(":-7\r\n"), new(int), -7, nil},
+		{"unmarshal_bulk_into_int", decode("$2\r\n42\r\n"), new(int64), int64(42), nil},
+		{"unmarshal_integer_overflow", decode(":300\r\n"), new(int8), int8(0), respser.ErrUnsupportedType},
+		{"unmarshal_integer_into_string", decode(":12\r\n"), new(string), "12", nil},
+		{"unmarshal_double", decode(",2.5\r\n"), new(float64), 2.5, nil},
+		{"unmarshal_bulk_into_float", decode("$3\r\ninf\r\n"), new(float64), math.Inf(1), nil},
+		{"unmarshal_boolean", decode("#t\r\n"), new(bool), true, nil},
+		{"unmarshal_integer_into_bool", decode(":0\r\n"), new(bool), false, nil},
+		{"unmarshal_null_into_pointer", decode("$-1\r\n"), func() any { p := ptr("x"); return &p }(), (*string)(nil), nil},
+		{"unmarshal_into_pointer", decode("$1\r\nx\r\n"), new(*string), ptr("x"), nil},
+		{"unmarshal_array", decode("*2\r\n$1\r\na\r\n$1\r\nb\r\n"), new([]string), []string{"a", "b"}, nil},
+		{"unmarshal_set", decode("~2\r\n:1\r\n:2\r\n"), new([]int), []int{1, 2}, nil},
+		{"unmarshal_fixed_array", decode("*1\r\n:5\r\n"), new([2]int), [2]int{5, 0}, nil},
+		{"unmarshal_map", decode("%2\r\n$1\r\na\r\n:1\r\n$1\r\nb\r\n:2\r\n"), new(map[string]int), map[string]int{"a": 1, "b": 2}, nil},
+		{"unmarshal_flat_array_into_map", decode("*2\r\n$1\r\na\r\n$1\r\n1\r\n"), new(map[string]int), map[string]int{"a": 1}, nil},
+		{"unmarshal_odd_array_into_map", decode("*1\r\n$1\r\na\r\n"), new(map[string]int), map[string]int(nil), respser.ErrUnsupportedType},
+		{
+			"unmarshal_struct",
+			decode("%5\r\n$4\r\nuser\r\n$3\r\nann\r\n$6\r\nVISITS\r\n:3\r\n$5\r\nadmin\r\n#t\r\n$4\r\ntags\r\n*1\r\n$1\r\nx\r\n$7\r\nunknown\r\n:1\r\n"),
+			new(session),
+			session{User: "ann", Visits: 3, Admin: true, Tags: []string{"x"}},
+			nil,
+		},
+		{
+			"unmarshal_flat_array_into_struct",
+			decode("*4\r\n$4\r\nuser\r\n$3\r\nbob\r\n$5\r\nscore\r\n$3\r\n1.5\r\n"),
+			new(session),
+			session{User: "bob", Score: 1.5},
+			nil,
+		},
+		{"unmarshal_into_any", decode("*3\r\n$1\r\na\r\n:1\r\n#f\r\n"), new(any), []any{"a", int64(1), false}, nil},
+		{"unmarshal_map_into_any", decode("%1\r\n$1\r\nk\r\n,1.5\r\n"), new(any), map[string]any{"k": 1.5}, nil},
+		{"unmarshal_into_resp_encoder", decode(":1\r\n"), new(respser.RespEncoder), &respser.Integer{N: 1}, nil},
+		{"unmarshal_error_reply", decode("-ERR no\r\n"), new(string), "", respser.ErrErrorReply},
+		{"unmarshal_array_into_string", decode("*1\r\n:1\r\n"), new(string), "", respser.ErrUnsupportedType},
+		{"unmarshal_into_non_pointer", decode(":1\r\n"), 5, nil, respser.ErrInvalidTarget},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := respser.Unmarshal(tc.input, tc.target)
+
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Expected error %v, Got %v", tc.err, err)
+			}
+			if tc.want == nil {
+				return
+			}
+			got := reflect.ValueOf(tc.target).Elem().Interface()
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %#v, Got %#v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMarshalUnmarshalRoundTrip(t *testing.T) {
+	in := session{User: "ann", Visits: 9, Score: 0.25, Tags: []string{"a", "b"}, Extra: map[string]string{"k": "v"}}
+
+	re, err := respser.Marshal(in)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for _, version := range []int{2, 3} {
+		var out session
+		if err := respser.Unmarshal(respser.ToProtocol(re, version), &out); err != nil {
+			t.Fatalf("RESP%d: unexpected error %v", version, err)
+		}
+		if !reflect.DeepEqual(out, in) {
+			t.Errorf("RESP%d: Expected %#v, Got %#v", version, in, out)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package respser
+
+// ToProtocol rewrites re so that it only uses types that exist in the given
+// protocol version (2 or 3), which lets a reply be built once and rendered
+// for each connection. For RESP2, maps are flattened into key/value arrays,
+// sets and pushes become arrays, booleans become 0/1 integers, doubles, big
+// numbers and verbatim strings become bulk strings and Null becomes the null
+// bulk string. For RESP3, null bulk strings and null arrays become Null.
+func ToProtocol(re RespEncoder, version int) RespEncoder {
+	if version >= 3 {
+		return toResp3(re)
+	}
+	return toResp2(re)
+}
+
+func toResp2(re RespEncoder) RespEncoder {
+	switch v := re.(type) {
+	case *Null:
+		return &BulkString{}
+	case *Boolean:
+		if v.B {
+			return &Integer{N: 1}
+		}
+		return &Integer{N: 0}
+	case *Double:
+		return NewBulkString(FormatDouble(v.F))
+	case *BigNumber:
+		return NewBulkString(v.digits())
+	case *BulkError:
+		return &ErrorString{E: v.E}
+	case *VerbatimString:
+		return NewBulkString(v.S)
+	case *Array:
+		if v.Elements == nil {
+			return v
+		}
+		return &Array{Elements: convertElements(*v.Elements, toResp2)}
+	case *Set:
+		return &Array{Elements: convertElements(v.Elements, toResp2)}
+	case *Push:
+		return &Array{Elements: convertElements(v.Elements, toResp2)}
+	case *Map:
+		return &Array{Elements: flattenEntries(v.Entries)}
+	case *Attribute:
+		return &Array{Elements: flattenEntries(v.Entries)}
+	default:
+		return re
+	}
+}
+
+func toResp3(re RespEncoder) RespEncoder {
+	switch v := re.(type) {
+	case *BulkString:
+		if v.S == nil {
+			return &Null{}
+		}
+		return v
+	case *Array:
+		if v.Elements == nil {
+			return &Null{}
+		}
+		return &Array{Elements: convertElements(*v.Elements, toResp3)}
+	case *Set:
+		return &Set{Elements: *convertElements(v.Elements, toResp3)}
+	case *Push:
+		return &Push{Elements: *convertElements(v.Elements, toResp3)}
+	case *Map:
+		return &Map{Entries: convertEntries(v.Entries, toResp3)}
+	case *Attribute:
+		return &Attribute{Entries: convertEntries(v.Entries, toResp3)}
+	default:
+		return re
+	}
+}
+
+func convertElements(elements []RespEncoder, convert func(RespEncoder) RespEncoder) *[]RespEncoder {
+	res := make([]RespEncoder, len(elements))
+	for i, e := range elements {
+		res[i] = convert(e)
+	}
+	return &res
+}
+
+func convertEntries(entries []MapEntry, convert func(RespEncoder) RespEncoder) []MapEntry {
+	res := make([]MapEntry, len(entries))
+	for i, e := range entries {
+		res[i] = MapEntry{Key: convert(e.Key), Value: convert(e.Value)}
+	}
+	return res
+}
+
+func flattenEntries(entries []MapEntry) *[]RespEncoder {
+	res := make([]RespEncoder, 0, 2*len(entries))
+	for _, e := range entries {
+		res = append(res, toResp2(e.Key), toResp2(e.Value))
+	}
+	return &res
+}
@@ -0,0 +1,68 @@
+package respser_test
+
+import (
+	"gored/respser"
+	"math"
+	"testing"
+)
+
+func TestToProtocol(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   respser.RespEncoder
+		version int
+		want    string
+	}{
+		{"resp2_null", &respser.Null{}, 2, "$-1\r\n"},
+		{"resp2_true", &respser.Boolean{B: true}, 2, ":1\r\n"},
+		{"resp2_false", &respser.Boolean{B: false}, 2, ":0\r\n"},
+		{"resp2_double", &respser.Double{F: 2.5}, 2, "$3\r\n2.5\r\n"},
+		{"resp2_infinite_double", &respser.Double{F: math.Inf(-1)}, 2, "$4\r\n-inf\r\n"},
+		{"resp2_big_number", &respser.BigNumber{N: bigInt("123456789012345678901234567890")}, 2, "$30\r\n123456789012345678901234567890\r\n"},
+		{"resp2_bulk_error", &respser.BulkError{E: "ERR oops"}, 2, "-ERR oops\r\n"},
+		{"resp2_verbatim_string", &respser.VerbatimString{Format: "txt", S: "hi"}, 2, "$2\r\nhi\r\n"},
+		{
+			"resp2_map_is_flattened",
+			&respser.Map{Entries: []respser.MapEntry{
+				{Key: respser.NewBulkString("a"), Value: &respser.Integer{N: 1}},
+				{Key: respser.NewBulkString("b"), Value: &respser.Null{}},
+			}},
+			2,
+			"*4\r\n$1\r\na\r\n:1\r\n$1\r\nb\r\n$-1\r\n",
+		},
+		{"resp2_set", &respser.Set{Elements: []respser.RespEncoder{&respser.Boolean{B: true}}}, 2, "*1\r\n:1\r\n"},
+		{"resp2_push", &respser.Push{Elements: []respser.RespEncoder{respser.NewBulkString("x")}}, 2, "*1\r\n$1\r\nx\r\n"},
+		{
+			"resp2_nested_array",
+			&respser.Array{Elements: &[]respser.RespEncoder{&respser.Map{}, &respser.Double{F: 1}}},
+			2,
+			"*2\r\n*0\r\n$1\r\n1\r\n",
+		},
+		{"resp2_null_array_unchanged", &respser.Array{}, 2, "*-1\r\n"},
+		{"resp2_null_bulk_unchanged", &respser.BulkString{}, 2, "$-1\r\n"},
+		{"resp3_null_bulk", &respser.BulkString{}, 3, "_\r\n"},
+		{"resp3_null_array", &respser.Array{}, 3, "_\r\n"},
+		{"resp3_bulk_string_unchanged", respser.NewBulkString("v"), 3, "$1\r\nv\r\n"},
+		{
+			"resp3_map_kept",
+			&respser.Map{Entries: []respser.MapEntry{{Key: respser.NewBulkString("a"), Value: &respser.BulkString{}}}},
+			3,
+			"%1\r\n$1\r\na\r\n_\r\n",
+		},
+		{
+			"resp3_nested_null",
+			&respser.Array{Elements: &[]respser.RespEncoder{&respser.BulkString{}, &respser.Set{}}},
+			3,
+			"*2\r\n_\r\n~0\r\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := respser.ToProtocol(tc.input, tc.version).RespEncode()
+			if res != tc.want {
+				t.Errorf("Expected: %q, Got: %q", tc.want, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package respser
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Reader reads RESP values from a stream. Unlike RespDecode it does not need
+// the whole input up front: each call to ReadValue blocks until one complete
+// value is available, however it was split across the underlying reads.
+type Reader struct {
+	rd     *bufio.Reader
+	limits Limits
+	offset int // bytes consumed so far, for locating errors in the stream
+}
+
+func NewReader(rd io.Reader) *Reader {
+	return &Reader{rd: bufio.NewReader(rd), limits: DefaultLimits}
+}
+
+// SetLimits replaces the limits the Reader enforces, which start out as
+// DefaultLimits. Limits are checked as soon as a length is read, before
+// the data it announces is buffered.
+func (r *Reader) SetLimits(limits Limits) {
+	r.limits = limits
+}
+
+// ReadValue reads exactly one RESP value and decodes it with ExtractType, so
+// it accepts and rejects the same inputs as the string based API.
+// It returns io.EOF if the stream ends cleanly before a value starts and
+// io.ErrUnexpectedEOF if it ends in the middle of one.
+func (r *Reader) ReadValue() (RespEncoder, error) {
+	var sb strings.Builder
+	start := r.offset
+	err := r.readFrame(&sb, nil)
+	r.offset += sb.Len()
+	if err != nil {
+		if errors.Is(err, io.EOF) && sb.Len() > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	in := sb.String()
+	x := newExtractor(r.limits, in)
+	x.base = start
+	re, r2, err := x.extractType(in)
+	if err != nil {
+		return nil, err
+	}
+	if r2 != "" {
+		return nil, x.fail(ErrInvalidInputData, "ReadValue", r2, 0)
+	}
+	return re, nil
+}
+
+// readFrame copies the raw bytes of one value into sb, following the length
+// prefixes of blob and aggregate types without interpreting anything else.
+// Errors carry the stream offset and the element path of the failing header.
+func (r *Reader) readFrame(sb *strings.Builder, path []int) error {
+	base := r.offset + sb.Len()
+	line, err := r.readLine(true)
+	sb.WriteString(line)
+	if err != nil {
+		return rebase(err, base)
+	}
+
+	x := &extractor{limits: r.limits, src: line, base: base, path: path}
+	switch line[0] {
+	case '$', '!', '=':
+		n, err := strconv.Atoi(line[1 : len(line)-len(CRLF)])
+		if err != nil {
+			return x.fail(ErrInvalidInputData, "ReadValue", line, 1)
+		}
+		if n < 0 {
+			return nil
+		}
+		if r.limits.MaxBulkLen > 0 && n > r.limits.MaxBulkLen {
+			return x.fail(ErrBulkLengthExceeded, "ReadValue", line, 1)
+		}
+		if _, err := io.CopyN(sb, r.rd, int64(n+len(CRLF))); err != nil {
+			return err
+		}
+	case '*', '~', '>', '%', '|':
+		n, err := strconv.Atoi(line[1 : len(line)-len(CRLF)])
+		if err != nil {
+			return x.fail(ErrInvalidInputData, "ReadValue", line, 1)
+		}
+		if err := x.checkAggregate("ReadValue", line, n); err != nil {
+			return err
+		}
+		if line[0] == '%' || line[0] == '|' {
+			n *= 2
+		}
+		for i := 0; i < n; i++ {
+			if err := r.readFrame(sb, append(path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readLine reads up to and including the next line terminator, failing once
+// the line grows past MaxInlineLen. If crlf is set only CRLF terminates the
+// line, matching the splitting done by the Extract functions; otherwise a
+// lone '\n' does too, as in inline commands.
+func (r *Reader) readLine(crlf bool) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.rd.ReadSlice('\n')
+		line = append(line, chunk...)
+		if r.limits.MaxInlineLen > 0 && len(line) > r.limits.MaxInlineLen {
+			return "", newRespSerError("ReadValue", string(line), ErrInlineTooLong, r.limits.MaxInlineLen)
+		}
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err != nil:
+			return string(line), err
+		case !crlf || bytes.HasSuffix(line, []byte(CRLF)):
+			return string(line), nil
+		}
+	}
+}
+
+// Buffered returns the number of bytes that have been received but not yet
+// consumed by ReadValue. A server can use it to tell whether more pipelined
+// input is already waiting.
+func (r *Reader) Buffered() int {
+	return r.rd.Buffered()
+}
+
+// ReadCommand reads one client request and returns its arguments. Requests
+// starting with '*' are read as RESP arrays; anything else is an inline
+// command, a single line split with SplitArgs, as typed into telnet or sent
+// by plain-text health checks. Inline arguments are returned as bulk strings
+// so both forms look the same to the caller. Empty lines are skipped.
+func (r *Reader) ReadCommand() ([]RespEncoder, error) {
+	for {
+		b, err := r.rd.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] == '*' {
+			re, err := r.ReadValue()
+			if err != nil {
+				return nil, err
+			}
+			return re.(*Array).GetElements(), nil
+		}
+
+		base := r.offset
+		line, err := r.readLine(false)
+		r.offset += len(line)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, rebase(err, base)
+		}
+		words, err := SplitArgs(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+		if err != nil {
+			return nil, rebase(err, base)
+		}
+		if len(words) == 0 {
+			continue
+		}
+		args := make([]RespEncoder, len(words))
+		for i, w := range words {
+			args[i] = NewBulkString(w)
+		}
+		return args, nil
+	}
+}
+
+// rebase moves the offset of an error found within a line to the position
+// of that line in the stream.
+func rebase(err error, base int) error {
+	var rse *RespSerError
+	if errors.As(err, &rse) {
+		rse.Offset += base
+	}
+	return err
+}
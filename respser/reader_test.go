@@ -0,0 +1,133 @@
+package respser_test
+
+import (
+	"errors"
+	"gored/respser"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReaderReadValue(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  respser.RespEncoder
+		err   error
+	}{
+		{"read_simple_string", "+OK\r\n", &respser.SimpleString{S: "OK"}, nil},
+		{"read_error_string", "-ERR failed\r\n", &respser.ErrorString{E: "ERR failed"}, nil},
+		{"read_integer", ":42\r\n", &respser.Integer{N: 42}, nil},
+		{"read_bulk_string", "$5\r\nhello\r\n", &respser.BulkString{S: ptr("hello")}, nil},
+		{"read_null_bulk_string", "$-1\r\n", &respser.BulkString{}, nil},
+		{"read_simple_string_with_newline", "+hello\nworld\r\n", &respser.SimpleString{S: "hello\nworld"}, nil},
+		{
+			"read_array",
+			"*2\r\n$4\r\nECHO\r\n$5\r\nhello\r\n",
+			&respser.Array{Elements: &[]respser.RespEncoder{&respser.BulkString{S: ptr("ECHO")}, &respser.BulkString{S: ptr("hello")}}},
+			nil,
+		},
+		{
+			"read_nested_array",
+			"*2\r\n*1\r\n:1\r\n+two\r\n",
+			&respser.Array{Elements: &[]respser.RespEncoder{&respser.Array{Elements: &[]respser.RespEncoder{&respser.Integer{N: 1}}}, &respser.SimpleString{S: "two"}}},
+			nil,
+		},
+		{"read_large_bulk_string", "$5000\r\n" + strings.Repeat("a", 5000) + "\r\n", &respser.BulkString{S: ptr(strings.Repeat("a", 5000))}, nil},
+		{"read_empty_stream", "", nil, io.EOF},
+		{"read_truncated_line", "+OK", nil, io.ErrUnexpectedEOF},
+		{"read_truncated_bulk_string", "$5\r\nhel", nil, io.ErrUnexpectedEOF},
+		{"read_truncated_array", "*2\r\n:1\r\n", nil, io.ErrUnexpectedEOF},
+		{"read_invalid_type", "hello\r\n", nil, respser.ErrInvalidType},
+		{"read_invalid_bulk_length", "$foo\r\nbar\r\n", nil, respser.ErrInvalidInputData},
+		{"read_invalid_array_length", "*foo\r\n", nil, respser.ErrInvalidInputData},
+		{"read_null", "_\r\n", &respser.Null{}, nil},
+		{"read_verbatim_string", "=9\r\ntxt:a\r\nbc\r\n", &respser.VerbatimString{Format: "txt", S: "a\r\nbc"}, nil},
+		{
+			"read_map",
+			"%2\r\n+a\r\n:1\r\n+b\r\n~1\r\n#t\r\n",
+			&respser.Map{Entries: []respser.MapEntry{
+				{Key: &respser.SimpleString{S: "a"}, Value: &respser.Integer{N: 1}},
+				{Key: &respser.SimpleString{S: "b"}, Value: &respser.Set{Elements: []respser.RespEncoder{&respser.Boolean{B: true}}}},
+			}},
+			nil,
+		},
+		{"read_truncated_map", "%1\r\n+a\r\n", nil, io.ErrUnexpectedEOF},
+		{"read_bulk_string_with_crlf", "$8\r\nfoo\r\nbar\r\n", &respser.BulkString{S: ptr("foo\r\nbar")}, nil},
+		{"read_bulk_string_length_mismatch", "$3\r\nfoobar\r\n", nil, respser.ErrDataMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := respser.NewReader(iotest.OneByteReader(strings.NewReader(tc.input)))
+			got, err := r.ReadValue()
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, Got %v", tc.want, got)
+			}
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Expected error %v, Got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestReaderReadValueSequence(t *testing.T) {
+	input := "*1\r\n$4\r\nPING\r\n:7\r\n$3\r\nfoo\r\n"
+	want := []respser.RespEncoder{
+		&respser.Array{Elements: &[]respser.RespEncoder{&respser.BulkString{S: ptr("PING")}}},
+		&respser.Integer{N: 7},
+		&respser.BulkString{S: ptr("foo")},
+	}
+
+	r := respser.NewReader(iotest.HalfReader(strings.NewReader(input)))
+	for i, w := range want {
+		got, err := r.ReadValue()
+		if err != nil {
+			t.Fatalf("value %d: unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("value %d: Expected %v, Got %v", i, w, got)
+		}
+	}
+
+	if _, err := r.ReadValue(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected error %v, Got %v", io.EOF, err)
+	}
+}
+
+func TestReaderReadCommand(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  []respser.RespEncoder
+		err   error
+	}{
+		{"read_multibulk_command", "*2\r\n$4\r\nECHO\r\n$2\r\nhi\r\n", []respser.RespEncoder{respser.NewBulkString("ECHO"), respser.NewBulkString("hi")}, nil},
+		{"read_inline_command", "PING\r\n", []respser.RespEncoder{respser.NewBulkString("PING")}, nil},
+		{"read_inline_command_with_lf", "ECHO hi\n", []respser.RespEncoder{respser.NewBulkString("ECHO"), respser.NewBulkString("hi")}, nil},
+		{"read_inline_command_with_quotes", "ECHO \"a b\"\r\n", []respser.RespEncoder{respser.NewBulkString("ECHO"), respser.NewBulkString("a b")}, nil},
+		{"read_inline_skips_empty_lines", "\r\n\r\nPING\r\n", []respser.RespEncoder{respser.NewBulkString("PING")}, nil},
+		{"read_inline_unbalanced_quotes", "ECHO \"hi\r\n", nil, respser.ErrUnbalancedQuotes},
+		{"read_inline_without_newline", "PING", nil, io.ErrUnexpectedEOF},
+		{"read_command_empty_stream", "", nil, io.EOF},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := respser.NewReader(iotest.OneByteReader(strings.NewReader(tc.input)))
+			got, err := r.ReadCommand()
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, Got %v", tc.want, got)
+			}
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Expected error %v, Got %v", tc.err, err)
+			}
+		})
+	}
+}
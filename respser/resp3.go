@@ -0,0 +1,275 @@
+package respser
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// The types in this file were introduced by RESP3. Clients opt into them with
+// HELLO 3; RESP2 connections never see them on the wire.
+
+type Null struct{}
+
+func (n *Null) RespEncode() string {
+	return string(n.AppendResp(nil))
+}
+
+func (n *Null) AppendResp(dst []byte) []byte {
+	return append(dst, "_\r\n"...)
+}
+
+func (n *Null) ToString() string {
+	return "Null"
+}
+
+type Boolean struct {
+	B bool
+}
+
+func (b *Boolean) RespEncode() string {
+	return string(b.AppendResp(nil))
+}
+
+func (b *Boolean) AppendResp(dst []byte) []byte {
+	if b.B {
+		return append(dst, "#t\r\n"...)
+	}
+	return append(dst, "#f\r\n"...)
+}
+
+func (b *Boolean) ToString() string {
+	return fmt.Sprintf("Boolean: %t", b.B)
+}
+
+type Double struct {
+	F float64
+}
+
+func (d *Double) RespEncode() string {
+	return string(d.AppendResp(nil))
+}
+
+func (d *Double) AppendResp(dst []byte) []byte {
+	dst = append(dst, ',')
+	dst = AppendDouble(dst, d.F)
+	return append(dst, CRLF...)
+}
+
+func (d *Double) ToString() string {
+	return fmt.Sprintf("Double: %s", FormatDouble(d.F))
+}
+
+// FormatDouble formats f the way Redis writes doubles, with fpconv_dtoa:
+// the shortest digits that round-trip, in fixed-point unless that takes
+// more than seven zeros before or after the digits, and inf, -inf or nan
+// for special values.
+func FormatDouble(f float64) string {
+	return string(AppendDouble(nil, f))
+}
+
+// AppendDouble appends the FormatDouble representation of f to dst.
+func AppendDouble(dst []byte, f float64) []byte {
+	switch {
+	case math.IsInf(f, 1):
+		return append(dst, "inf"...)
+	case math.IsInf(f, -1):
+		return append(dst, "-inf"...)
+	case math.IsNaN(f):
+		return append(dst, "nan"...)
+	}
+
+	// The shortest digits d.ddd and exponent x, so f is the digits
+	// followed by k zeros, or with -k of them after the point.
+	var buf [32]byte
+	e := strconv.AppendFloat(buf[:0], f, 'e', -1, 64)
+	i := bytes.IndexByte(e, 'e')
+	mantissa := e[:i]
+	x, _ := strconv.Atoi(string(e[i+1:]))
+	digits := len(mantissa) - bytes.Count(mantissa, []byte(".")) - bytes.Count(mantissa, []byte("-"))
+	k := x - (digits - 1)
+	if k >= 0 && k < 8 || k < 0 && (k > -7 || x > -4 && x < 4) {
+		return strconv.AppendFloat(dst, f, 'f', -1, 64)
+	}
+
+	// Unlike strconv, fpconv_dtoa does not pad the exponent.
+	dst = append(dst, mantissa...)
+	dst = append(dst, 'e', e[i+1])
+	return strconv.AppendInt(dst, int64(max(x, -x)), 10)
+}
+
+type BigNumber struct {
+	N *big.Int
+}
+
+func (bn *BigNumber) RespEncode() string {
+	return string(bn.AppendResp(nil))
+}
+
+func (bn *BigNumber) AppendResp(dst []byte) []byte {
+	dst = append(dst, '(')
+	if bn.N == nil {
+		dst = append(dst, '0')
+	} else {
+		dst = bn.N.Append(dst, 10)
+	}
+	return append(dst, CRLF...)
+}
+
+func (bn *BigNumber) ToString() string {
+	return fmt.Sprintf("BigNumber: %s", bn.digits())
+}
+
+func (bn *BigNumber) digits() string {
+	if bn.N == nil {
+		return "0"
+	}
+	return bn.N.String()
+}
+
+type BulkError struct {
+	E string
+}
+
+func (be *BulkError) RespEncode() string {
+	return string(be.AppendResp(nil))
+}
+
+func (be *BulkError) AppendResp(dst []byte) []byte {
+	return appendBlob(dst, '!', be.E)
+}
+
+func (be *BulkError) ToString() string {
+	return fmt.Sprintf("BulkError: %s", be.E)
+}
+
+// VerbatimString is a bulk string tagged with a three character format such
+// as "txt" or "mkd".
+type VerbatimString struct {
+	Format string
+	S      string
+}
+
+func (vs *VerbatimString) RespEncode() string {
+	return string(vs.AppendResp(nil))
+}
+
+func (vs *VerbatimString) AppendResp(dst []byte) []byte {
+	dst = appendLength(dst, '=', int64(len(vs.Format)+1+len(vs.S)))
+	dst = append(dst, vs.Format...)
+	dst = append(dst, ':')
+	dst = append(dst, vs.S...)
+	return append(dst, CRLF...)
+}
+
+func (vs *VerbatimString) ToString() string {
+	return fmt.Sprintf("VerbatimString: %s:%s", vs.Format, vs.S)
+}
+
+type MapEntry struct {
+	Key   RespEncoder
+	Value RespEncoder
+}
+
+type Map struct {
+	Entries []MapEntry
+}
+
+func (m *Map) RespEncode() string {
+	return string(m.AppendResp(nil))
+}
+
+func (m *Map) AppendResp(dst []byte) []byte {
+	return appendEntries(dst, '%', m.Entries)
+}
+
+func (m *Map) ToString() string {
+	return "Map: " + entriesToString(m.Entries)
+}
+
+func (m *Map) AddEntry(key, value RespEncoder) {
+	m.Entries = append(m.Entries, MapEntry{Key: key, Value: value})
+}
+
+type Set struct {
+	Elements []RespEncoder
+}
+
+func (s *Set) RespEncode() string {
+	return string(s.AppendResp(nil))
+}
+
+func (s *Set) AppendResp(dst []byte) []byte {
+	return appendElements(dst, '~', s.Elements)
+}
+
+func (s *Set) ToString() string {
+	return "Set: " + elementsToString(s.Elements)
+}
+
+func (s *Set) AddElement(element RespEncoder) {
+	s.Elements = append(s.Elements, element)
+}
+
+// Push is an out of band message such as a pub/sub notification. By
+// convention its first element names the kind of message.
+type Push struct {
+	Elements []RespEncoder
+}
+
+func (p *Push) RespEncode() string {
+	return string(p.AppendResp(nil))
+}
+
+func (p *Push) AppendResp(dst []byte) []byte {
+	return appendElements(dst, '>', p.Elements)
+}
+
+func (p *Push) ToString() string {
+	return "Push: " + elementsToString(p.Elements)
+}
+
+func (p *Push) AddElement(element RespEncoder) {
+	p.Elements = append(p.Elements, element)
+}
+
+// Attribute carries auxiliary key/value data about the reply that follows it
+// on the wire. It is extracted as a value of its own; the reply it describes
+// is left in the remainder.
+type Attribute struct {
+	Entries []MapEntry
+}
+
+func (a *Attribute) RespEncode() string {
+	return string(a.AppendResp(nil))
+}
+
+func (a *Attribute) AppendResp(dst []byte) []byte {
+	return appendEntries(dst, '|', a.Entries)
+}
+
+func (a *Attribute) ToString() string {
+	return "Attribute: " + entriesToString(a.Entries)
+}
+
+func (a *Attribute) AddEntry(key, value RespEncoder) {
+	a.Entries = append(a.Entries, MapEntry{Key: key, Value: value})
+}
+
+func elementsToString(elements []RespEncoder) string {
+	s := ""
+	for _, e := range elements {
+		s = s + e.ToString()
+	}
+	return s
+}
+
+func entriesToString(entries []MapEntry) string {
+	s := ""
+	for _, e := range entries {
+		s = s + e.Key.ToString() + " => " + e.Value.ToString() + "; "
+	}
+	return s
+}
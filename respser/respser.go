@@ -15,46 +15,101 @@ var (
 	ErrInvalidInputParts = errors.New("invalid input parts")
 	ErrFailedExtraction  = errors.New("failed extraction")
 	ErrDecode            = errors.New("decode error")
+	ErrUnbalancedQuotes  = errors.New("unbalanced quotes")
+	ErrUnsupportedType   = errors.New("unsupported type")
+	ErrInvalidTarget     = errors.New("invalid unmarshal target")
+	ErrErrorReply        = errors.New("error reply")
+
+	ErrBulkLengthExceeded      = errors.New("bulk length exceeds limit")
+	ErrMultibulkLengthExceeded = errors.New("multibulk length exceeds limit")
+	ErrNestingTooDeep          = errors.New("nesting too deep")
+	ErrInlineTooLong           = errors.New("inline request too long")
 )
 
 type RespSerError struct {
 	Func string // the failing function (RespEncode, RespDecode)
 	In   string // the input
 	Err  error  // the reason the conversion failed (e.g. ErrInvalidType, ErrInvalidInputParts, etc.)
+
+	// Offset is the byte offset of the failure from the start of the
+	// outermost input, or of the stream for values read by a Reader.
+	Offset int
+	// Path holds the element indexes leading from the outermost aggregate
+	// to the failing value; it is empty at the top level.
+	Path    []int
+	Excerpt string // the input around Offset, truncated to a few bytes
 }
 
 func (e *RespSerError) Error() string {
-	return "respser." + e.Func + ": " + "parsing " + e.In + ": " + e.Err.Error()
+	s := "respser." + e.Func + ": parsing " + strconv.Quote(e.Excerpt) + " at offset " + strconv.Itoa(e.Offset)
+	if len(e.Path) > 0 {
+		s += " in element " + fmt.Sprint(e.Path)
+	}
+	return s + ": " + e.Err.Error()
 }
 
 func (e *RespSerError) Unwrap() error { return e.Err }
 
+// excerptRadius is how many bytes of input an error shows on each side of
+// its offset.
+const excerptRadius = 16
+
+func excerpt(in string, offset int) string {
+	start, end := max(offset-excerptRadius, 0), min(offset+excerptRadius, len(in))
+	if start > end {
+		start = end
+	}
+	s := in[start:end]
+	if start > 0 {
+		s = "..." + s
+	}
+	if end < len(in) {
+		s += "..."
+	}
+	return s
+}
+
+func newRespSerError(fn string, in string, err error, offset int) *RespSerError {
+	return &RespSerError{Func: fn, In: in, Err: err, Offset: offset, Excerpt: excerpt(in, offset)}
+}
+
 func invalidTypeError(fn string, in string) *RespSerError {
-	return &RespSerError{fn, in, ErrInvalidType}
+	return newRespSerError(fn, in, ErrInvalidType, 0)
 }
 
 func invalidInputDataError(fn string, in string) *RespSerError {
-	return &RespSerError{fn, in, ErrInvalidInputData}
+	return newRespSerError(fn, in, ErrInvalidInputData, 0)
 }
 
 func dataMismatchError(fn string, in string) *RespSerError {
-	return &RespSerError{fn, in, ErrDataMismatch}
+	return newRespSerError(fn, in, ErrDataMismatch, 0)
 }
 
 func invalidInputPartsError(fn string, in string) *RespSerError {
-	return &RespSerError{fn, in, ErrInvalidInputParts}
+	return newRespSerError(fn, in, ErrInvalidInputParts, 0)
 }
 
-func failedExtractionError(fn string, in string) *RespSerError {
-	return &RespSerError{fn, in, ErrFailedExtraction}
+func decodeError(fn string, in string) *RespSerError {
+	return newRespSerError(fn, in, ErrDecode, 0)
 }
 
-func decodeError(fn string, in string) *RespSerError {
-	return &RespSerError{fn, in, ErrDecode}
+func unsupportedTypeError(fn string, in string) *RespSerError {
+	return newRespSerError(fn, in, ErrUnsupportedType, 0)
+}
+
+func invalidTargetError(fn string, in string) *RespSerError {
+	return newRespSerError(fn, in, ErrInvalidTarget, 0)
+}
+
+func errorReplyError(fn string, in string) *RespSerError {
+	return newRespSerError(fn, in, ErrErrorReply, 0)
 }
 
 type RespEncoder interface {
 	RespEncode() string
+	// AppendResp appends the RESP encoding to dst and returns the extended
+	// buffer, allowing replies to be built without intermediate strings.
+	AppendResp(dst []byte) []byte
 	ToString() string
 }
 
@@ -63,7 +118,11 @@ type SimpleString struct {
 }
 
 func (ss *SimpleString) RespEncode() string {
-	return fmt.Sprintf("+%s%s", ss.S, CRLF)
+	return string(ss.AppendResp(nil))
+}
+
+func (ss *SimpleString) AppendResp(dst []byte) []byte {
+	return appendLine(dst, '+', ss.S)
 }
 
 func (ss *SimpleString) ToString() string {
@@ -75,7 +134,11 @@ type ErrorString struct {
 }
 
 func (es *ErrorString) RespEncode() string {
-	return fmt.Sprintf("-%s\r\n", es.E)
+	return string(es.AppendResp(nil))
+}
+
+func (es *ErrorString) AppendResp(dst []byte) []byte {
+	return appendLine(dst, '-', es.E)
 }
 
 func (es *ErrorString) ToString() string {
@@ -83,27 +146,53 @@ func (es *ErrorString) ToString() string {
 }
 
 type Integer struct {
-	N int
+	N int64
 }
 
 func (i *Integer) RespEncode() string {
-	nStr := strconv.Itoa(i.N)
-	return fmt.Sprintf(":%s\r\n", nStr)
+	return string(i.AppendResp(nil))
+}
+
+func (i *Integer) AppendResp(dst []byte) []byte {
+	return appendLength(dst, ':', i.N)
 }
 
 func (i *Integer) ToString() string {
 	return fmt.Sprintf("Integer: %d", i.N)
 }
 
+// BulkString is a length prefixed, binary safe string. A nil S is the RESP
+// null bulk string.
 type BulkString struct {
 	S *string
 }
 
+func NewBulkString(s string) *BulkString {
+	return &BulkString{S: &s}
+}
+
+func NewBulkBytes(b []byte) *BulkString {
+	s := string(b)
+	return &BulkString{S: &s}
+}
+
+// Bytes returns the payload as a byte slice, or nil for the null bulk string.
+func (bs *BulkString) Bytes() []byte {
+	if bs.S == nil {
+		return nil
+	}
+	return []byte(*bs.S)
+}
+
 func (bs *BulkString) RespEncode() string {
+	return string(bs.AppendResp(nil))
+}
+
+func (bs *BulkString) AppendResp(dst []byte) []byte {
 	if bs.S == nil {
-		return "$-1\r\n"
+		return append(dst, "$-1\r\n"...)
 	}
-	return fmt.Sprintf("$%d\r\n%s\r\n", len(*bs.S), *bs.S)
+	return appendBlob(dst, '$', *bs.S)
 }
 
 func (bs *BulkString) ToString() string {
@@ -121,14 +210,14 @@ type Array struct {
 }
 
 func (a *Array) RespEncode() string {
+	return string(a.AppendResp(nil))
+}
+
+func (a *Array) AppendResp(dst []byte) []byte {
 	if a.Elements == nil {
-		return "*-1\r\n"
-	}
-	res := fmt.Sprintf("*%d\r\n", len(*a.Elements))
-	for _, e := range *a.Elements {
-		res = res + e.RespEncode()
+		return append(dst, "*-1\r\n"...)
 	}
-	return res
+	return appendElements(dst, '*', *a.Elements)
 }
 
 func (a *Array) ToString() string {
@@ -152,3 +241,38 @@ func (a *Array) GetElements() []RespEncoder {
 	}
 	return *a.Elements
 }
+
+func appendLine(dst []byte, prefix byte, line string) []byte {
+	dst = append(dst, prefix)
+	dst = append(dst, line...)
+	return append(dst, CRLF...)
+}
+
+func appendLength(dst []byte, prefix byte, n int64) []byte {
+	dst = append(dst, prefix)
+	dst = strconv.AppendInt(dst, n, 10)
+	return append(dst, CRLF...)
+}
+
+func appendBlob(dst []byte, prefix byte, payload string) []byte {
+	dst = appendLength(dst, prefix, int64(len(payload)))
+	dst = append(dst, payload...)
+	return append(dst, CRLF...)
+}
+
+func appendElements(dst []byte, prefix byte, elements []RespEncoder) []byte {
+	dst = appendLength(dst, prefix, int64(len(elements)))
+	for _, e := range elements {
+		dst = e.AppendResp(dst)
+	}
+	return dst
+}
+
+func appendEntries(dst []byte, prefix byte, entries []MapEntry) []byte {
+	dst = appendLength(dst, prefix, int64(len(entries)))
+	for _, e := range entries {
+		dst = e.Key.AppendResp(dst)
+		dst = e.Value.AppendResp(dst)
+	}
+	return dst
+}
@@ -1,7 +1,12 @@
 package respser_test
 
 import (
+	"errors"
 	"gored/respser"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -79,6 +84,8 @@ func TestEncodeBulkStrings(t *testing.T) {
 		{"encode_non_empty_string", respser.BulkString{S: ptr("hello")}, "$5\r\nhello\r\n"},
 		{"encode_empty_string", respser.BulkString{S: ptr("")}, "$0\r\n\r\n"},
 		{"encode_nil_string", respser.BulkString{}, "$-1\r\n"},
+		{"encode_string_with_crlf", respser.BulkString{S: ptr("foo\r\nbar")}, "$8\r\nfoo\r\nbar\r\n"},
+		{"encode_binary_string", *respser.NewBulkBytes([]byte{0, 255, '\r', '\n'}), "$4\r\n\x00\xff\r\n\r\n"},
 	}
 
 	for _, tc := range testCases {
@@ -135,3 +142,247 @@ func TestEncodeArray(t *testing.T) {
 		})
 	}
 }
+
+func TestBulkStringBytes(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input *respser.BulkString
+		want  []byte
+	}{
+		{"bytes_of_text", respser.NewBulkString("hello"), []byte("hello")},
+		{"bytes_of_binary", respser.NewBulkBytes([]byte{0, '\r', '\n', 255}), []byte{0, '\r', '\n', 255}},
+		{"bytes_of_empty", respser.NewBulkString(""), []byte{}},
+		{"bytes_of_nil", &respser.BulkString{}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := tc.input.Bytes()
+			if !reflect.DeepEqual(res, tc.want) {
+				t.Errorf("Expected: %v, Got: %v", tc.want, res)
+			}
+		})
+	}
+}
+
+func TestEncodeResp3Scalars(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input respser.RespEncoder
+		want  string
+	}{
+		{"encode_null", &respser.Null{}, "_\r\n"},
+		{"encode_true", &respser.Boolean{B: true}, "#t\r\n"},
+		{"encode_false", &respser.Boolean{B: false}, "#f\r\n"},
+		{"encode_double", &respser.Double{F: 1.23}, ",1.23\r\n"},
+		{"encode_integral_double", &respser.Double{F: 10}, ",10\r\n"},
+		{"encode_negative_double", &respser.Double{F: -0.5}, ",-0.5\r\n"},
+		{"encode_million_double", &respser.Double{F: 1e6}, ",1000000\r\n"},
+		{"encode_large_fraction_double", &respser.Double{F: 1234567.5}, ",1234567.5\r\n"},
+		{"encode_double_seven_zeros", &respser.Double{F: 1.5e8}, ",150000000\r\n"},
+		{"encode_double_exponent", &respser.Double{F: 1e8}, ",1e+8\r\n"},
+		{"encode_double_many_digits_exponent", &respser.Double{F: 1.23e10}, ",1.23e+10\r\n"},
+		{"encode_max_double", &respser.Double{F: math.MaxFloat64}, ",1.7976931348623157e+308\r\n"},
+		{"encode_small_double", &respser.Double{F: 1e-5}, ",0.00001\r\n"},
+		{"encode_smaller_double", &respser.Double{F: -1e-6}, ",-0.000001\r\n"},
+		{"encode_tiny_double", &respser.Double{F: 1.5e-7}, ",1.5e-7\r\n"},
+		{"encode_long_fraction_double", &respser.Double{F: 0.30000000000000004}, ",0.30000000000000004\r\n"},
+		{"encode_smallest_double", &respser.Double{F: 5e-324}, ",5e-324\r\n"},
+		{"encode_zero_double", &respser.Double{F: 0}, ",0\r\n"},
+		{"encode_positive_infinity", &respser.Double{F: math.Inf(1)}, ",inf\r\n"},
+		{"encode_negative_infinity", &respser.Double{F: math.Inf(-1)}, ",-inf\r\n"},
+		{"encode_nan", &respser.Double{F: math.NaN()}, ",nan\r\n"},
+		{"encode_big_number", &respser.BigNumber{N: bigInt("3492890328409238509324850943850943825024385")}, "(3492890328409238509324850943850943825024385\r\n"},
+		{"encode_negative_big_number", &respser.BigNumber{N: big.NewInt(-12)}, "(-12\r\n"},
+		{"encode_bulk_error", &respser.BulkError{E: "SYNTAX invalid syntax"}, "!21\r\nSYNTAX invalid syntax\r\n"},
+		{"encode_bulk_error_with_crlf", &respser.BulkError{E: "ERR a\r\nb"}, "!8\r\nERR a\r\nb\r\n"},
+		{"encode_verbatim_string", &respser.VerbatimString{Format: "txt", S: "Some string"}, "=15\r\ntxt:Some string\r\n"},
+		{"encode_empty_verbatim_string", &respser.VerbatimString{Format: "mkd"}, "=4\r\nmkd:\r\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := tc.input.RespEncode()
+			if res != tc.want {
+				t.Errorf("Expected: %s, Got: %s", tc.want, res)
+			}
+		})
+	}
+}
+
+func TestEncodeResp3Aggregates(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input respser.RespEncoder
+		want  string
+	}{
+		{
+			"encode_map",
+			&respser.Map{Entries: []respser.MapEntry{
+				{Key: &respser.SimpleString{S: "first"}, Value: &respser.Integer{N: 1}},
+				{Key: &respser.SimpleString{S: "second"}, Value: &respser.Integer{N: 2}},
+			}},
+			"%2\r\n+first\r\n:1\r\n+second\r\n:2\r\n",
+		},
+		{"encode_empty_map", &respser.Map{}, "%0\r\n"},
+		{
+			"encode_set",
+			&respser.Set{Elements: []respser.RespEncoder{&respser.BulkString{S: ptr("a")}, &respser.Boolean{B: true}}},
+			"~2\r\n$1\r\na\r\n#t\r\n",
+		},
+		{"encode_empty_set", &respser.Set{}, "~0\r\n"},
+		{
+			"encode_push",
+			&respser.Push{Elements: []respser.RespEncoder{&respser.BulkString{S: ptr("message")}, &respser.BulkString{S: ptr("ch")}, &respser.BulkString{S: ptr("hi")}}},
+			">3\r\n$7\r\nmessage\r\n$2\r\nch\r\n$2\r\nhi\r\n",
+		},
+		{
+			"encode_attribute",
+			&respser.Attribute{Entries: []respser.MapEntry{
+				{Key: &respser.SimpleString{S: "ttl"}, Value: &respser.Integer{N: 3600}},
+			}},
+			"|1\r\n+ttl\r\n:3600\r\n",
+		},
+		{
+			"encode_nested_map",
+			&respser.Map{Entries: []respser.MapEntry{
+				{Key: &respser.BulkString{S: ptr("k")}, Value: &respser.Set{Elements: []respser.RespEncoder{&respser.Null{}}}},
+			}},
+			"%1\r\n$1\r\nk\r\n~1\r\n_\r\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := tc.input.RespEncode()
+			if res != tc.want {
+				t.Errorf("Expected: %s, Got: %s", tc.want, res)
+			}
+		})
+	}
+}
+
+func TestRespSerErrorLocation(t *testing.T) {
+	// readSecond reads two values from one stream and returns the error of
+	// the second, so offsets must count the bytes of the first.
+	readSecond := func(in string, read func(*respser.Reader) error) func() error {
+		return func() error {
+			r := respser.NewReader(strings.NewReader(in))
+			if err := read(r); err != nil {
+				t.Fatalf("invalid test input %q: %v", in, err)
+			}
+			return read(r)
+		}
+	}
+	readValue := func(r *respser.Reader) error { _, err := r.ReadValue(); return err }
+	readCommand := func(r *respser.Reader) error { _, err := r.ReadCommand(); return err }
+
+	testCases := []struct {
+		name   string
+		call   func() error
+		offset int
+		path   []int
+		err    error
+	}{
+		{
+			"short_bulk_in_array",
+			func() error { _, _, err := respser.ExtractType("*2\r\n:1\r\n$5\r\nab\r\n"); return err },
+			16, []int{1}, respser.ErrDataMismatch,
+		},
+		{
+			"nested_array",
+			func() error { _, _, err := respser.ExtractType("*2\r\n:1\r\n*1\r\n#x\r\n"); return err },
+			13, []int{1, 0}, respser.ErrInvalidInputData,
+		},
+		{
+			"map_value",
+			func() error { _, _, err := respser.ExtractMap("%1\r\n+k\r\n:x\r\n"); return err },
+			9, []int{1}, respser.ErrInvalidInputData,
+		},
+		{
+			"decode_trailing_data",
+			func() error { _, err := respser.RespDecode("*1\r\n:1\r\n:2\r\n"); return err },
+			8, nil, respser.ErrInvalidInputData,
+		},
+		{
+			"reader_second_value",
+			readSecond("+OK\r\n*1\r\n:x\r\n", readValue),
+			10, []int{0}, respser.ErrInvalidInputData,
+		},
+		{
+			"reader_nested_header",
+			readSecond("+OK\r\n*1\r\n*2\r\n$x\r\n", readValue),
+			14, []int{0, 0}, respser.ErrInvalidInputData,
+		},
+		{
+			"reader_inline_command",
+			readSecond("PING\r\nSET \"a\r\n", readCommand),
+			12, nil, respser.ErrUnbalancedQuotes,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Expected error %v, Got %v", tc.err, err)
+			}
+			var rse *respser.RespSerError
+			if !errors.As(err, &rse) {
+				t.Fatalf("Expected a RespSerError, Got %T", err)
+			}
+			if rse.Offset != tc.offset {
+				t.Errorf("Expected offset %d, Got %d", tc.offset, rse.Offset)
+			}
+			if len(rse.Path) != 0 || len(tc.path) != 0 {
+				if !reflect.DeepEqual(rse.Path, tc.path) {
+					t.Errorf("Expected path %v, Got %v", tc.path, rse.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestRespSerErrorMessage(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			"short_input",
+			"*1\r\n#x\r\n",
+			`respser.extractBoolean: parsing "*1\r\n#x\r\n" at offset 5 in element [0]: invalid input data`,
+		},
+		{
+			"long_input_is_truncated",
+			"*1\r\n+" + strings.Repeat("a", 100),
+			`respser.extractSimpleString: parsing "...aaaaaaaaaaaaaaaa" at offset 105 in element [0]: invalid input parts`,
+		},
+		{
+			"long_input_around_failure",
+			"*2\r\n+" + strings.Repeat("b", 40) + "\r\n:x" + strings.Repeat("c", 30) + "\r\n",
+			`respser.extractInteger: parsing "...bbbbbbbbbbbbb\r\n:xccccccccccccccc..." at offset 48 in element [1]: invalid input data`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := respser.ExtractType(tc.input)
+
+			var rse *respser.RespSerError
+			if !errors.As(err, &rse) {
+				t.Fatalf("Expected a RespSerError, Got %v", err)
+			}
+			if rse.Error() != tc.want {
+				t.Errorf("Expected %s, Got %s", tc.want, rse.Error())
+			}
+		})
+	}
+}
+
+func bigInt(s string) *big.Int {
+	n, _ := new(big.Int).SetString(s, 10)
+	return n
+}
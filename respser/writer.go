@@ -0,0 +1,41 @@
+package respser
+
+import (
+	"bufio"
+	"io"
+)
+
+// maxRetainedScratch bounds the encoding buffer a Writer keeps between
+// values, so one huge reply does not pin its memory for the connection's life.
+const maxRetainedScratch = 64 * 1024
+
+// Writer encodes RESP values onto a buffered stream. Values are appended to
+// a scratch buffer that is reused across calls, so writing a reply does not
+// allocate once the buffer has grown to the usual reply size.
+type Writer struct {
+	wr      *bufio.Writer
+	scratch []byte
+}
+
+func NewWriter(wr io.Writer) *Writer {
+	return &Writer{wr: bufio.NewWriter(wr)}
+}
+
+// WriteValue buffers the encoding of re. Call Flush to send it.
+func (w *Writer) WriteValue(re RespEncoder) error {
+	w.scratch = re.AppendResp(w.scratch[:0])
+	_, err := w.wr.Write(w.scratch)
+	if cap(w.scratch) > maxRetainedScratch {
+		w.scratch = nil
+	}
+	return err
+}
+
+func (w *Writer) Flush() error {
+	return w.wr.Flush()
+}
+
+// Buffered returns the number of bytes written but not yet flushed.
+func (w *Writer) Buffered() int {
+	return w.wr.Buffered()
+}
@@ -0,0 +1,131 @@
+package respser_test
+
+import (
+	"bytes"
+	"gored/respser"
+	"io"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+func TestAppendResp(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input respser.RespEncoder
+	}{
+		{"append_simple_string", &respser.SimpleString{S: "OK"}},
+		{"append_error_string", &respser.ErrorString{E: "ERR oops"}},
+		{"append_integer", &respser.Integer{N: -42}},
+		{"append_bulk_string", respser.NewBulkString("foo\r\nbar")},
+		{"append_nil_bulk_string", &respser.BulkString{}},
+		{"append_nil_array", &respser.Array{}},
+		{"append_nested_array", &respser.Array{Elements: &[]respser.RespEncoder{&respser.Integer{N: 1}, &respser.Array{Elements: &[]respser.RespEncoder{respser.NewBulkString("x")}}}}},
+		{"append_null", &respser.Null{}},
+		{"append_boolean", &respser.Boolean{B: true}},
+		{"append_double", &respser.Double{F: 0.1}},
+		{"append_big_number", &respser.BigNumber{N: big.NewInt(-7)}},
+		{"append_bulk_error", &respser.BulkError{E: "ERR x"}},
+		{"append_verbatim_string", &respser.VerbatimString{Format: "txt", S: "hi"}},
+		{"append_map", &respser.Map{Entries: []respser.MapEntry{{Key: respser.NewBulkString("k"), Value: &respser.Integer{N: 1}}}}},
+		{"append_set", &respser.Set{Elements: []respser.RespEncoder{&respser.Integer{N: 1}}}},
+		{"append_push", &respser.Push{Elements: []respser.RespEncoder{respser.NewBulkString("message")}}},
+		{"append_attribute", &respser.Attribute{Entries: []respser.MapEntry{{Key: respser.NewBulkString("k"), Value: &respser.Null{}}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prefix := []byte("+prefix\r\n")
+			res := tc.input.AppendResp(append([]byte{}, prefix...))
+			want := string(prefix) + tc.input.RespEncode()
+			if string(res) != want {
+				t.Errorf("Expected: %q, Got: %q", want, res)
+			}
+		})
+	}
+}
+
+func TestWriterWriteValue(t *testing.T) {
+	var out bytes.Buffer
+	w := respser.NewWriter(&out)
+	values := []respser.RespEncoder{
+		&respser.SimpleString{S: "PONG"},
+		respser.NewBulkString("hello"),
+		&respser.Array{Elements: &[]respser.RespEncoder{&respser.Integer{N: 1}, &respser.BulkString{}}},
+	}
+	for _, v := range values {
+		if err := w.WriteValue(v); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing written before Flush, Got %q", out.String())
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	want := "+PONG\r\n$5\r\nhello\r\n*2\r\n:1\r\n$-1\r\n"
+	if out.String() != want {
+		t.Errorf("Expected: %q, Got: %q", want, out.String())
+	}
+}
+
+func largeArray(n int) *respser.Array {
+	a := &respser.Array{}
+	for i := 0; i < n; i++ {
+		a.AddElement(respser.NewBulkString("element:" + strconv.Itoa(i)))
+	}
+	return a
+}
+
+func BenchmarkRespEncodeArray(b *testing.B) {
+	a := largeArray(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = a.RespEncode()
+	}
+}
+
+func BenchmarkAppendRespArray(b *testing.B) {
+	a := largeArray(1000)
+	buf := a.AppendResp(nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = a.AppendResp(buf[:0])
+	}
+}
+
+func BenchmarkRespEncodeBulkString(b *testing.B) {
+	bs := respser.NewBulkString("hello world")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = bs.RespEncode()
+	}
+}
+
+func BenchmarkAppendRespBulkString(b *testing.B) {
+	bs := respser.NewBulkString("hello world")
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = bs.AppendResp(buf[:0])
+	}
+}
+
+func BenchmarkWriterWriteValue(b *testing.B) {
+	a := largeArray(1000)
+	w := respser.NewWriter(io.Discard)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := w.WriteValue(a); err != nil {
+			b.Fatal(err)
+		}
+	}
+}